@@ -0,0 +1,278 @@
+// Package codegen compiles templates parsed by the parse package into Go code.
+//
+// For every template it generates a Payload_* struct holding the template params,
+// a New* constructor, a Render(templates.RenderContext) error method and
+// a RenderBlock_* method per block - the code prototype/code shows.
+//
+// A template extended by other templates is generated as a layout:
+// an interface with RenderBlock_* methods of all its blocks, implemented by extending templates,
+// and an unexported struct rendering the layout with blocks of the most derived template.
+// Blocks an extending template does not override fall back to the layout's content,
+// {{ super() }} in an overridden block renders it.
+//
+// Printed values are escaped for their context: the compiler runs the HTML parser of
+// inspiration/builtin/html over the static text and picks escapers for each action,
+// e.g. templates.EscapeJSVal in <script> or templates.EscapeAttr in a quoted attribute.
+// Blocks are escaped for the context they are declared in. Values of safe content types,
+// e.g. a param of type templates.HTML, are written unescaped where the context matches their type.
+//
+// @include("path", param=value ...) renders another template in place; arguments are
+// checked against params of the included template at compile time. Types of fields selected by
+// chained arguments, e.g. author.Name, are looked up in the package of the param type the template imports.
+//
+// @cache(block[, ttl="1h"]) caches HTML of the block in templates.Fragments by the template,
+// the payload and the locale; on a cache hit the block is not rendered. Unlike FragmentCache.Component,
+// a hit does not skip GetData: the block is looked up while rendering, after GetData of the template,
+// as data of the block comes with the payload the key is computed from.
+//
+// With Catalogs set, keys of translation calls are checked against translations of the required locales:
+// missing ones are reported or fail the build, see TranslationCheck.
+// Translations of static keys can be inlined into render paths specialized for InlineLocales.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/parse"
+)
+
+// TemplateExt is the extension of template files compiled by CompileDir.
+const TemplateExt = ".html"
+
+// DefaultI18nFunc is the function generated constructors call to get templates.I18n for a locale.
+// It should be defined in the package of the generated code.
+const DefaultI18nFunc = "GetI18N"
+
+// TranslationCheck tells how the generator treats translation keys missing from catalogs.
+type TranslationCheck int
+
+const (
+	IgnoreMissingTranslations TranslationCheck = iota // keys are not checked
+	ReportMissingTranslations                         // missing translations are reported to CodeGenerator.Report
+	FailOnMissingTranslations                         // a missing translation fails compilation of the template
+)
+
+// CodeGenerator generates Go code from a set of templates.
+type CodeGenerator struct {
+	environment templates.StrongoEnvironment
+
+	PackageName string // Name of the generated package; CompileDir defaults it to the name of the output dir.
+	I18nFunc    string // Function returning templates.I18n for a locale; DefaultI18nFunc if empty.
+
+	// Keys of _(), _n(), _p() and _np() calls with literal strings are checked against Catalogs:
+	// each must be translated to every required locale, by the catalog of the locale or of its base language.
+	Catalogs         *templates.Catalogs
+	RequiredLocales  []string // Locales checked; all locales of Catalogs but the default one if empty.
+	TranslationCheck TranslationCheck
+	Report           io.Writer // Where missing translations are reported; os.Stderr if nil.
+
+	// InlineLocales are locales render methods get a specialized path for, with translations of static keys
+	// by Catalogs merged into the adjacent static text. The path is taken when templates.LocaleOf
+	// the I18n of the template is one of them, so the I18n should translate with the same catalogs.
+	InlineLocales []string
+
+	templates map[string]*sourceTemplate
+	packages  types.Importer // packages of types of params, see TemplateToGoCodeCompiler.chainType
+}
+
+// sourceTemplate is a parsed template registered with the generator.
+type sourceTemplate struct {
+	name   string // path relative to the templates root, always with forward slashes
+	source string
+	tree   *parse.Tree
+}
+
+func NewCodeGenerator(e templates.StrongoEnvironment) *CodeGenerator {
+	g := new(CodeGenerator)
+	g.environment = e
+	g.templates = make(map[string]*sourceTemplate)
+	return g
+}
+
+// AddTemplate parses the template source and registers it under the given name.
+// Names are paths relative to the templates root, e.g. "folder/header.html".
+func (g *CodeGenerator) AddTemplate(name, source string) error {
+	if _, ok := g.templates[name]; ok {
+		return fmt.Errorf("template %q is already added", name)
+	}
+	tree, err := parse.New(name).Parse(source, "", "", "", make(map[string]*parse.Tree))
+	if err != nil {
+		return err
+	}
+	g.templates[name] = &sourceTemplate{name: name, source: source, tree: tree}
+	return nil
+}
+
+// Names returns sorted names of registered templates.
+func (g *CodeGenerator) Names() []string {
+	names := make([]string, 0, len(g.templates))
+	for name := range g.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compile writes Go code for the registered template to the writer.
+func (g *CodeGenerator) Compile(name string, writer io.Writer) error {
+	t, ok := g.templates[name]
+	if !ok {
+		return fmt.Errorf("unknown template %q", name)
+	}
+	if g.PackageName == "" {
+		return fmt.Errorf("package name is not set")
+	}
+	c := &TemplateToGoCodeCompiler{g: g, template: t}
+	return c.Compile(writer)
+}
+
+// CompileDir compiles all templates found in inputDir and writes generated files to outputDir.
+func (g *CodeGenerator) CompileDir(inputDir, outputDir string) error {
+	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != TemplateExt {
+			return nil
+		}
+		name, err := filepath.Rel(inputDir, path)
+		if err != nil {
+			return err
+		}
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return g.AddTemplate(filepath.ToSlash(name), string(source))
+	})
+	if err != nil {
+		return err
+	}
+	if g.PackageName == "" {
+		outputDir, err := filepath.Abs(outputDir)
+		if err != nil {
+			return err
+		}
+		g.PackageName = filepath.Base(outputDir)
+	}
+	if err = os.MkdirAll(outputDir, 0775); err != nil {
+		return err
+	}
+	for _, name := range g.Names() {
+		buffer := new(bytes.Buffer)
+		if err = g.Compile(name, buffer); err != nil {
+			return err
+		}
+		if err = ioutil.WriteFile(filepath.Join(outputDir, GoFileName(name)), buffer.Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importer returns the importer of packages type checked from source, created on first use.
+func (g *CodeGenerator) importer() types.Importer {
+	if g.packages == nil {
+		g.packages = importer.ForCompiler(token.NewFileSet(), "source", nil)
+	}
+	return g.packages
+}
+
+// isExtended reports whether any registered template extends the template.
+func (g *CodeGenerator) isExtended(name string) bool {
+	for _, t := range g.templates {
+		if t.tree.Extends != nil && t.tree.Extends.Path == name {
+			return true
+		}
+	}
+	return false
+}
+
+// missingTranslations returns the required locales lacking a translation of the key in the context.
+func (g *CodeGenerator) missingTranslations(context, key string) (locales []string) {
+	required := g.RequiredLocales
+	if len(required) == 0 {
+		required = g.Catalogs.Locales()[1:]
+	}
+	for _, locale := range required {
+		translated := false
+		for _, l := range templates.LocaleChain(locale, "") {
+			if catalog := g.Catalogs.Catalog(l); catalog != nil {
+				if translation, ok := catalog.Translation(templates.ContextKey(context, key)); ok && strings.Trim(translation, "\x00") != "" {
+					translated = true
+					break
+				}
+			}
+		}
+		if !translated {
+			locales = append(locales, locale)
+		}
+	}
+	return locales
+}
+
+func (g *CodeGenerator) report() io.Writer {
+	if g.Report == nil {
+		return os.Stderr
+	}
+	return g.Report
+}
+
+func (g *CodeGenerator) i18nFunc() string {
+	if g.I18nFunc == "" {
+		return DefaultI18nFunc
+	}
+	return g.I18nFunc
+}
+
+// TypeName returns name of the Go type generated for the template, e.g. "Index_html" for "index.html".
+func TypeName(name string) string {
+	s := []rune(identifier(name))
+	if !unicode.IsLetter(s[0]) {
+		return "Template_" + string(s)
+	}
+	s[0] = unicode.ToUpper(s[0])
+	return string(s)
+}
+
+// layoutTypeName returns name of the unexported struct generated for the template extended by others,
+// e.g. "layout_html" for "layout.html".
+func layoutTypeName(name string) string {
+	s := []rune(TypeName(name))
+	s[0] = unicode.ToLower(s[0])
+	return string(s)
+}
+
+// GoFileName returns name of the Go file generated for the template, e.g. "index_html.go" for "index.html".
+func GoFileName(name string) string {
+	return identifier(name) + ".go"
+}
+
+// identifier replaces all characters that are not allowed in Go identifiers with underscores.
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// exportedName capitalizes the first letter of the name.
+func exportedName(name string) string {
+	s := []rune(name)
+	s[0] = unicode.ToUpper(s[0])
+	return string(s)
+}
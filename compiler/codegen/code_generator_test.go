@@ -0,0 +1,660 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/templates"
+)
+
+type compileTest struct {
+	name      string
+	templates map[string]string
+	contains  []string // snippets expected in the code generated for the template with the test name
+	err       string   // expected error substring; empty if compilation should succeed
+}
+
+var compileTests = []compileTest{
+	{"text.html", map[string]string{
+		"text.html": `Hello, world!`,
+	}, []string{
+		"// Code generated by strongo from text.html. DO NOT EDIT.",
+		"package code",
+		"type Payload_Text_html struct {\n}",
+		"func NewText_html(locale string, payload Payload_Text_html) templates.Template {\n\treturn newText_html(GetI18N(locale), payload)\n}",
+		`if _, err := c.WriteString("Hello, world!"); err != nil {`,
+		"func (t *Text_html) Name() string {\n\treturn \"text.html\"\n}",
+	}, ""},
+	{"folder/params.html", map[string]string{
+		"folder/params.html": "@params(\n\tname string\n\tcount int\n)\nHello, {{ name }}! You have {{ count }} messages.",
+	}, []string{
+		"type Payload_Folder_params_html struct {\n\tName  string\n\tCount int\n}",
+		"c.WriteString(templates.EscapeHTML(t.payload.Name))",
+		"c.WriteString(templates.EscapeHTML(t.payload.Count))",
+		"func (t *Folder_params_html) Path() string {\n\treturn \"folder/params.html\"\n}",
+	}, ""},
+	{"i18n.html", map[string]string{
+		"i18n.html": `<h1>{{ _("Welcome to page") }}</h1>`,
+	}, []string{
+		`c.WriteString(templates.EscapeHTML(t.i18n.GetText("Welcome to page")))`,
+	}, ""},
+	{"blocks.html", map[string]string{
+		"blocks.html": `<body>{{ block body }}<h1>{{ block title }}Title{{ endblock }}</h1>{{ endblock }}</body>`,
+	}, []string{
+		"\tif _, err := c.WriteString(\"<body>\"); err != nil {\n\t\treturn err\n\t}\n\tif err := t.RenderBlock_body(c); err != nil {",
+		"func (t *Blocks_html) RenderBlock_body(c templates.RenderContext) error {",
+		"func (t *Blocks_html) RenderBlock_title(c templates.RenderContext) error {\n\tif _, err := c.WriteString(\"Title\"); err != nil {",
+	}, ""},
+	{"imports.html", map[string]string{
+		"imports.html": "@import(\n\t\"github.com/strongo/templates/prototype\"\n\tm \"github.com/strongo/templates/prototype/models\"\n\t\"gopkg.in/yaml.v2\"\n)\n" +
+			"@params(author *prototype.Author, options yaml.MapSlice)\n{{ author.Name }}",
+	}, []string{
+		"\t\"github.com/strongo/templates\"\n\t\"github.com/strongo/templates/prototype\"\n\t\"gopkg.in/yaml.v2\"\n)",
+		"Author  *prototype.Author",
+		"c.WriteString(templates.EscapeHTML(t.payload.Author.Name))",
+	}, ""},
+	{"payload.html", map[string]string{
+		"payload.html": "@params(\n\tp1 string required\n\tp2 int optional = 5\n\tp3 *Author required\n\tp4 map[string][]int\n\tp5 Status = \"active\"\n)",
+	}, []string{
+		"type Payload_Payload_html struct {\n\tP1 string // required\n\tP2 int\n\tP3 *Author // required\n\tP4 map[string][]int\n\tP5 Status\n}",
+		"func NewPayload_Payload_html(payload Payload_Payload_html) (Payload_Payload_html, error) {\n" +
+			"\tif payload.P1 == \"\" {\n\t\treturn payload, templates.MissingParamError{Template: \"payload.html\", Param: \"p1\"}\n\t}\n" +
+			"\tif payload.P2 == 0 {\n\t\tpayload.P2 = 5\n\t}\n" +
+			"\tif payload.P3 == nil {\n\t\treturn payload, templates.MissingParamError{Template: \"payload.html\", Param: \"p3\"}\n\t}\n" +
+			"\tif templates.IsZero(payload.P5) {\n\t\tpayload.P5 = \"active\"\n\t}\n" +
+			"\treturn payload, nil\n}",
+		"\tpayload, err := NewPayload_Payload_html(payload)\n\treturn &Payload_html{",
+		"func (t *Payload_html) Render(c templates.RenderContext) error {\n\tif err := t.render(c); err != nil {\n\t\treturn err\n\t}\n\treturn c.RenderPending()\n}",
+		"func (t *Payload_html) render(c templates.RenderContext) error {\n\tif t.err != nil {\n\t\treturn t.err\n\t}",
+	}, ""},
+	{"layout.html", map[string]string{
+		"base.html":   `<body style="{{ block body_style }}{{ endblock }}">{{ block body }}{{ endblock }}</body>`,
+		"layout.html": "@extends(\"base.html\")\n@params(bgColor string)\n{{ block body_style }}background-color: {{ bgColor }}{{ endblock }}\n{{ block body }}<h1>{{ block title }}Title{{ endblock }}</h1>{{ block menu }}Menu{{ endblock }}{{ endblock }}\n",
+		"index.html":  "@extends(\"layout.html\")\n@params(p1 string required)\n{{ block title }}{{ p1 }}{{ endblock }}\n{{ block body_style }}{{ super() }}; color: red{{ endblock }}\n",
+	}, []string{
+		"type Layout_html interface {\n\tRenderBlock_body_style(c templates.RenderContext) error\n\tRenderBlock_body(c templates.RenderContext) error\n" +
+			"\tRenderBlock_title(c templates.RenderContext) error\n\tRenderBlock_menu(c templates.RenderContext) error\n}",
+		"func New_layout_html(i18n templates.I18n, template Layout_html, payload Payload_Layout_html) layout_html {",
+		"\tt.extends = New_base_html(i18n, template, Payload_Base_html{})\n\treturn t\n}",
+		"func (t layout_html) Render(c templates.RenderContext) error {\n\tif t.err != nil {\n\t\treturn t.err\n\t}\n\treturn t.extends.Render(c)\n}",
+		"if err := t.template.RenderBlock_title(c); err != nil {",
+		"func (t layout_html) RenderBlock_menu(c templates.RenderContext) error {\n\tif _, err := c.WriteString(\"Menu\"); err != nil {",
+	}, ""},
+	{"base.html", map[string]string{
+		"base.html":   `<body style="{{ block body_style }}{{ endblock }}">{{ block body }}{{ endblock }}</body>`,
+		"layout.html": "@extends(\"base.html\")\n@params(bgColor string)\n{{ block body_style }}background-color: {{ bgColor }}{{ endblock }}\n{{ block body }}<h1>{{ block title }}Title{{ endblock }}</h1>{{ block menu }}Menu{{ endblock }}{{ endblock }}\n",
+		"index.html":  "@extends(\"layout.html\")\n@params(p1 string required)\n{{ block title }}{{ p1 }}{{ endblock }}\n{{ block body_style }}{{ super() }}; color: red{{ endblock }}\n",
+	}, []string{
+		"type Base_html interface {\n\tRenderBlock_body_style(c templates.RenderContext) error\n\tRenderBlock_body(c templates.RenderContext) error\n}",
+		"func (t base_html) Render(c templates.RenderContext) error {\n\tif t.err != nil {\n\t\treturn t.err\n\t}\n\tif _, err := c.WriteString(\"<body style=\\\"\"); err != nil {",
+		"func (t base_html) GetData(ctx context.Context) error {\n\treturn t.err\n}",
+	}, ""},
+	{"index.html", map[string]string{
+		"base.html":   `<body style="{{ block body_style }}{{ endblock }}">{{ block body }}{{ endblock }}</body>`,
+		"layout.html": "@extends(\"base.html\")\n@params(bgColor string)\n{{ block body_style }}background-color: {{ bgColor }}{{ endblock }}\n{{ block body }}<h1>{{ block title }}Title{{ endblock }}</h1>{{ block menu }}Menu{{ endblock }}{{ endblock }}\n",
+		"index.html":  "@extends(\"layout.html\")\n@params(p1 string required)\n{{ block title }}{{ p1 }}{{ endblock }}\n{{ block body_style }}{{ super() }}; color: red{{ endblock }}\n",
+	}, []string{
+		"type Payload_Index_html struct {\n\tP1      string // required\n\tBgColor string\n}",
+		"\textends layout_html\n}",
+		"\tt.extends = New_layout_html(i18n, t, Payload_Layout_html{\n\t\tBgColor: payload.BgColor,\n\t})\n\treturn t\n}",
+		"func (t *Index_html) RenderBlock_title(c templates.RenderContext) error {\n\tif _, err := c.WriteString(templates.EscapeHTML(t.payload.P1)); err != nil {",
+		"func (t *Index_html) RenderBlock_menu(c templates.RenderContext) error {\n\treturn t.extends.RenderBlock_menu(c)\n}",
+		"func (t *Index_html) RenderBlock_body_style(c templates.RenderContext) error {\n\tif err := t.extends.RenderBlock_body_style(c); err != nil {\n\t\treturn err\n\t}",
+		"func (t *Index_html) GetData(ctx context.Context) error {\n\tif t.err != nil {\n\t\treturn t.err\n\t}\n\treturn t.extends.GetData(ctx)\n}",
+	}, ""},
+	{"undeclared.html", map[string]string{
+		"base.html":       `{{ block body }}{{ endblock }}`,
+		"undeclared.html": "@extends(\"base.html\")\n{{ block header }}{{ endblock }}",
+	}, nil, `template: undeclared.html:2:9: block "header" is not declared by base.html`},
+	{"orphan.html", map[string]string{
+		"orphan.html": `@extends("missing.html")`,
+	}, nil, `template extends unknown template "missing.html"`},
+	{"cyclic.html", map[string]string{
+		"cyclic.html": `@extends("other.html")`,
+		"other.html":  `@extends("cyclic.html")`,
+	}, nil, `cyclic @extends of "cyclic.html"`},
+	{"conflict.html", map[string]string{
+		"base.html":     `@params(p string)`,
+		"conflict.html": "@extends(\"base.html\")\n@params(p int)",
+	}, nil, `param "p" of type int conflicts with param of type string declared by base.html`},
+	{"super_root.html", map[string]string{
+		"super_root.html": `{{ super() }}`,
+	}, nil, "super() outside of block"},
+	{"super_standalone.html", map[string]string{
+		"super_standalone.html": `{{ block a }}{{ super() }}{{ endblock }}`,
+	}, nil, `super() in block "a" that is not declared by a parent template`},
+	{"super_new.html", map[string]string{
+		"base.html":      `{{ block a }}{{ endblock }}`,
+		"super_new.html": "@extends(\"base.html\"){{ block a }}{{ block b }}{{ super() }}{{ endblock }}{{ endblock }}",
+	}, nil, `super() in block "b" that is not declared by a parent template`},
+	{"super_args.html", map[string]string{
+		"base.html":       `{{ block a }}{{ endblock }}`,
+		"super_args.html": "@extends(\"base.html\"){{ block a }}{{ super(1) }}{{ endblock }}",
+	}, nil, "super expects no arguments, got 1"},
+	{"super_expression.html", map[string]string{
+		"base.html":             `{{ block a }}{{ endblock }}`,
+		"super_expression.html": "@extends(\"base.html\"){{ block a }}{{ _(super()) }}{{ endblock }}",
+	}, nil, "super() can only be used as an action"},
+	{"include1.html", map[string]string{
+		"include1.html": "@params(p1 string)\n@include(\"include2.html\", p11=p1, p12=5)",
+		"include2.html": "@params(\n\tp11 string required\n\tp12 float64\n)\n{{ p11 }}",
+	}, []string{
+		"\tif err := newInclude2_html(t.i18n, Payload_Include2_html{P11: t.payload.P1, P12: 5}).render(c); err != nil {\n\t\treturn err\n\t}",
+	}, ""},
+	{"include_missing.html", map[string]string{
+		"include_missing.html": `@include("include2.html", p12=1.5)`,
+		"include2.html":        "@params(p11 string required, p12 float64)",
+	}, nil, `template: include_missing.html:1:1: missing required param "p11" of include2.html`},
+	{"include_type.html", map[string]string{
+		"include_type.html": "@params(p1 int)\n@include(\"include2.html\", p11=p1)",
+		"include2.html":     "@params(p11 string)",
+	}, nil, `cannot use p1 (type int) as string value in argument "p11"`},
+	{"include_literal.html", map[string]string{
+		"include_literal.html": `@include("include2.html", p11=1)`,
+		"include2.html":        "@params(p11 string)",
+	}, nil, `cannot use 1 as string value in argument "p11"`},
+	{"include_chain.html", map[string]string{
+		"include_chain.html": "@import(\"github.com/strongo/templates/prototype\")\n@params(author *prototype.Author)\n@include(\"include2.html\", p11=author.Name)",
+		"include2.html":      "@params(p11 string)",
+	}, []string{
+		"newInclude2_html(t.i18n, Payload_Include2_html{P11: t.payload.Author.Name}).render(c)",
+	}, ""},
+	{"include_chain_type.html", map[string]string{
+		"include_chain_type.html": "@import(\"github.com/strongo/templates/prototype\")\n@params(author prototype.Author)\n@include(\"include2.html\", p11=author.Id)",
+		"include2.html":           "@params(p11 string)",
+	}, nil, `cannot use author.Id (type int) as string value in argument "p11"`},
+	{"include_chain_unknown.html", map[string]string{
+		"include_chain_unknown.html": "@params(author *Author)\n@include(\"include2.html\", p11=author.Name)",
+		"include2.html":              "@params(p11 string)",
+	}, nil, `cannot resolve type of author.Name: param type Author is not a type of an imported package`},
+	{"include_param.html", map[string]string{
+		"include_param.html": `@include("include2.html", p2="x")`,
+		"include2.html":      "@params(p11 string)",
+	}, nil, `unknown param "p2" of include2.html`},
+	{"include_unknown.html", map[string]string{
+		"include_unknown.html": `@include("include2.html")`,
+	}, nil, `include of unknown template "include2.html"`},
+	{"include_layout.html", map[string]string{
+		"include_layout.html": `@include("base.html")`,
+		"base.html":           `{{ block a }}{{ endblock }}`,
+		"page.html":           `@extends("base.html")`,
+	}, nil, "can not include base.html as it is extended by other templates"},
+	{"cached.html", map[string]string{
+		"base.html":   "@cache(menu)\n{{ block menu }}{{ endblock }}{{ block body }}{{ endblock }}",
+		"cached.html": "@extends(\"base.html\")\n@cache(body, ttl=\"90s\")\n{{ block body }}Body{{ endblock }}",
+	}, []string{
+		"func (t *Cached_html) RenderBlock_menu(c templates.RenderContext) error {\n\tkey := templates.FragmentKey{\n\t\tTemplate: \"cached.html\",\n\t\tBlock:    \"menu\",\n\t\tPayload:  templates.PayloadHash(t.payload),\n\t\tLocale:   templates.LocaleOf(t.i18n),\n\t}\n\treturn templates.Fragments.Render(c, key, 0, t.renderBlock_menu)\n}",
+		"func (t *Cached_html) renderBlock_menu(c templates.RenderContext) error {\n\treturn t.extends.RenderBlock_menu(c)\n}",
+		"return templates.Fragments.Render(c, key, 90*time.Second, t.renderBlock_body)",
+		"func (t *Cached_html) renderBlock_body(c templates.RenderContext) error {\n\tif _, err := c.WriteString(\"Body\"); err != nil {",
+	}, ""},
+	{"cached_layout.html", map[string]string{
+		"cached_layout.html": "@cache(menu)\n{{ block menu }}{{ endblock }}",
+		"page.html":          `@extends("cached_layout.html")`,
+	}, []string{
+		"func (t cached_layout_html) RenderBlock_menu(c templates.RenderContext) error {\n\treturn nil\n}",
+	}, ""},
+	{"cached_unknown.html", map[string]string{
+		"cached_unknown.html": "@cache(menu)",
+	}, nil, `template: cached_unknown.html:1:1: @cache of unknown block "menu"`},
+	{"default_type.html", map[string]string{
+		"default_type.html": `@params(p int = "x")`,
+	}, nil, `cannot use "x" as int value in default of param "p"`},
+	{"default_float.html", map[string]string{
+		"default_float.html": `@params(p int = 1.5)`,
+	}, nil, `cannot use 1.5 as int value in default of param "p"`},
+	{"required_bool.html", map[string]string{
+		"required_bool.html": `@params(p bool required)`,
+	}, nil, `bool param "p" can not be required`},
+	{"undefined.html", map[string]string{
+		"undefined.html": "Hello, {{ name }}!",
+	}, nil, "template: undefined.html:1:10: undefined: name"},
+	{"plural.html", map[string]string{
+		"plural.html": "@params(count int64, name string)\n{{ _n(\"%d message\", \"%d messages\", count) }}{{ _(\"Hi, {name}!\", name=name) }}" +
+			"{{ _n(\"%d message from {name}\", \"%d messages from {name}\", 5, name=name) }}",
+	}, []string{
+		`c.WriteString(templates.EscapeHTML(t.i18n.NGetText("%d message", "%d messages", int(t.payload.Count))))`,
+		`c.WriteString(templates.EscapeHTML(t.i18n.Format(t.i18n.GetText("Hi, {name}!"), map[string]interface{}{"name": t.payload.Name})))`,
+		`t.i18n.Format(t.i18n.NGetText("%d message from {name}", "%d messages from {name}", 5), map[string]interface{}{"name": t.payload.Name})`,
+	}, ""},
+	{"plural_count.html", map[string]string{
+		"plural_count.html": "@params(count float64)\n{{ _n(\"%d message\", \"%d messages\", count) }}",
+	}, nil, "cannot use count (type float64) as plural count"},
+	{"plural_args.html", map[string]string{
+		"plural_args.html": `{{ _n("%d message", 1) }}`,
+	}, nil, "_n expects 3 arguments, got 2"},
+	{"context.html", map[string]string{
+		"context.html": "@params(count int)\n{{ _p(\"menu\", \"Open\") }}{{ _np(\"inbox\", \"%d message\", \"%d messages\", count) }}",
+	}, []string{
+		`c.WriteString(templates.EscapeHTML(templates.PGetText(t.i18n, "menu", "Open")))`,
+		`c.WriteString(templates.EscapeHTML(templates.NPGetText(t.i18n, "inbox", "%d message", "%d messages", t.payload.Count)))`,
+	}, ""},
+	{"escape.html", map[string]string{
+		"escape.html": "@params(p string, n int)\n" +
+			`<a href="{{ p }}/search?q={{ p }}" title="{{ p }}" class={{ p }} onclick="f({{ n }})">{{ p }}</a>` +
+			`<script>var s = "{{ p }}", n = {{ n }};</script><style>p { color: {{ p }} }</style><textarea>{{ p }}</textarea>`,
+	}, []string{
+		"c.WriteString(templates.EscapeAttr(templates.NormalizeURL(templates.FilterURL(t.payload.P))))",
+		"c.WriteString(templates.EscapeAttr(templates.EscapeURL(t.payload.P)))",
+		"c.WriteString(\"\\\" title=\\\"\")",
+		"c.WriteString(templates.EscapeAttr(t.payload.P))",
+		"c.WriteString(templates.EscapeUnquotedAttr(t.payload.P))",
+		"c.WriteString(templates.EscapeAttr(templates.EscapeJSVal(t.payload.N)))",
+		"c.WriteString(templates.EscapeHTML(t.payload.P))",
+		"c.WriteString(templates.EscapeJSStr(t.payload.P))",
+		"c.WriteString(templates.EscapeJSVal(t.payload.N))",
+		"c.WriteString(templates.FilterCSSValue(t.payload.P))",
+		"c.WriteString(templates.EscapeRCDATA(t.payload.P))",
+	}, ""},
+	{"style.html", map[string]string{
+		"base.html":  `<body style="{{ block body_style }}{{ endblock }}">{{ block body }}{{ endblock }}</body>`,
+		"style.html": "@extends(\"base.html\")\n@params(color string)\n{{ block body_style }}color: {{ color }}{{ endblock }}",
+	}, []string{
+		"func (t *Style_html) RenderBlock_body_style(c templates.RenderContext) error {\n\tif _, err := c.WriteString(\"color: \"); err != nil {\n\t\treturn err\n\t}\n" +
+			"\tif _, err := c.WriteString(templates.EscapeAttr(templates.FilterCSSValue(t.payload.Color))); err != nil {",
+	}, ""},
+	{"safe.html", map[string]string{
+		"safe.html": "@params(body templates.HTML, script templates.JS, style templates.CSS, link templates.URL, images templates.Srcset)\n" +
+			`<a href="{{ link }}" title="{{ body }}">{{ body }}</a><img srcset="{{ images }}">` +
+			`<script>f({{ script }})</script><p style="{{ style }}">{{ style }}</p>`,
+	}, []string{
+		"c.WriteString(templates.EscapeAttr(templates.NormalizeURL(t.payload.Link)))",
+		"c.WriteString(templates.EscapeAttr(t.payload.Body))",
+		"c.WriteString(string(t.payload.Body))",
+		"c.WriteString(templates.EscapeAttr(t.payload.Images))",
+		"c.WriteString(string(t.payload.Script))",
+		"c.WriteString(templates.EscapeAttr(t.payload.Style))",
+		"c.WriteString(templates.EscapeHTML(t.payload.Style))",
+	}, ""},
+	{"unquoted.html", map[string]string{
+		"unquoted.html": "@params(p string)\n<a title={{ p }}\"x\">",
+	}, nil, `template: unquoted.html:2:16: "\"" in unquoted attr: "\"x\""`},
+	{"end.html", map[string]string{
+		"end.html": "@params(p string)\n<script>var p = {{ p }};",
+	}, nil, "template: end.html:2:23: template ends in a non-text context: {stateJS"},
+	{"block_end.html", map[string]string{
+		"base.html":      `<p>{{ block a }}{{ endblock }}</p>`,
+		"block_end.html": "@extends(\"base.html\")\n{{ block a }}<b title=\"{{ endblock }}",
+	}, nil, `block "a" ends in context {stateAttr delimDoubleQuote`},
+	{"block_moved.html", map[string]string{
+		"base.html":        `<p>{{ block a }}{{ block b }}{{ endblock }}{{ endblock }}</p>`,
+		"block_moved.html": "@extends(\"base.html\")\n{{ block a }}<script>{{ block b }}{{ endblock }}</script>{{ endblock }}",
+	}, nil, `block "b" is in context {stateJS`},
+	{"include_context.html", map[string]string{
+		"include_context.html": "<p title=\"@include(\"include2.html\")\">",
+		"include2.html":        "Hi",
+	}, nil, "@include in a non-text context"},
+	{"function.html", map[string]string{
+		"function.html": `{{ unknown("x") }}`,
+	}, nil, "undefined function: unknown"},
+}
+
+func TestCompile(t *testing.T) {
+	for _, test := range compileTests {
+		g := NewCodeGenerator(templates.StrongoEnvironment{})
+		g.PackageName = "code"
+		for name, source := range test.templates {
+			if err := g.AddTemplate(name, source); err != nil {
+				t.Fatalf("%s: failed to add template %s: %v", test.name, name, err)
+			}
+		}
+		buffer := new(bytes.Buffer)
+		err := g.Compile(test.name, buffer)
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected error %q, got: %v", test.name, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		code := buffer.String()
+		for _, s := range test.contains {
+			if !strings.Contains(code, s) {
+				t.Errorf("%s: generated code does not contain:\n%s\n--- code:\n%s", test.name, s, code)
+			}
+		}
+	}
+}
+
+func TestTranslationCheck(t *testing.T) {
+	catalogs := templates.NewCatalogs("en_US")
+	catalogs.Add("ru", templates.Messages{"Hi": "Привет", "%d message": "%d сообщение\x00%d сообщения\x00%d сообщений"})
+	catalogs.Add("ru_RU", templates.Messages{"menu\x04Open": "Открыть"})
+	catalogs.Add("de", templates.Messages{"Hi": "Hallo", "Empty": ""})
+	source := "@params(key string)\n{{ _(\"Hi\") }}{{ _(\"Empty\") }}{{ _(key) }}\n" +
+		"{{ _n(\"%d message\", \"%d messages\", 2) }}{{ _p(\"menu\", \"Open\") }}"
+	for _, test := range []struct {
+		check    TranslationCheck
+		required []string
+		err      string
+		report   string
+	}{
+		{check: IgnoreMissingTranslations},
+		{check: ReportMissingTranslations, report: `template: index.html:2:16: "Empty" has no translation to de, ru, ru_RU
+template: index.html:3:3: "%d message" has no translation to de
+template: index.html:3:43: "Open" has no translation to de, ru
+`},
+		{check: FailOnMissingTranslations, required: []string{"ru_RU"}, err: `template: index.html:2:16: "Empty" has no translation to ru_RU`},
+		{check: FailOnMissingTranslations, required: []string{"ru_RU", "ru_UA"},
+			err: "index.html:2:16: \"Empty\" has no translation to ru_RU, ru_UA\ntemplate: index.html:3:43: \"Open\" has no translation to ru_UA"},
+	} {
+		g := NewCodeGenerator(templates.StrongoEnvironment{})
+		g.PackageName = "code"
+		g.Catalogs, g.RequiredLocales, g.TranslationCheck = catalogs, test.required, test.check
+		report := new(bytes.Buffer)
+		g.Report = report
+		if err := g.AddTemplate("index.html", source); err != nil {
+			t.Fatal(err)
+		}
+		err := g.Compile("index.html", new(bytes.Buffer))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", test.check, err)
+		case test.err != "" && (err == nil || !strings.Contains(err.Error(), test.err)):
+			t.Errorf("%v: expected error %q, got: %v", test.check, test.err, err)
+		}
+		if report.String() != test.report {
+			t.Errorf("%v: expected report\n%s\ngot\n%s", test.check, test.report, report)
+		}
+	}
+}
+
+func TestInlineLocales(t *testing.T) {
+	g := NewCodeGenerator(templates.StrongoEnvironment{})
+	g.PackageName = "code"
+	g.InlineLocales = []string{"en_US", "ru_RU"}
+	if err := g.AddTemplate("index.html", "@params(key string)\n<h1>{{ _(\"Hi\") }} & {{ _p(\"menu\", \"<Open>\") }}</h1>{{ _(key) }}{{ block menu }}Menu{{ endblock }}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Compile("index.html", new(bytes.Buffer)); err == nil || !strings.Contains(err.Error(), "inlined locales need catalogs") {
+		t.Errorf("expected an error of missing catalogs, got %v", err)
+	}
+	g.Catalogs = templates.NewCatalogs("en_US")
+	g.Catalogs.Add("ru", templates.Messages{"Hi": "Привет", "menu\x04<Open>": "<Открыть>"})
+	buffer := new(bytes.Buffer)
+	if err := g.Compile("index.html", buffer); err != nil {
+		t.Fatal(err)
+	}
+	code := buffer.String()
+	for _, s := range []string{
+		"\tlocale  int // 1 + index of the locale in inlined ones, 0 if its translations are not inlined.\n",
+		"\t\tlocale:  templates.LocaleIndex(i18n, \"en_US\", \"ru_RU\"),\n",
+		"\tswitch t.locale {\n\tcase 1: // en_US\n\t\tif _, err := c.WriteString(\"\\n<h1>Hi & &lt;Open&gt;</h1>\"); err != nil {",
+		"\tcase 2: // ru_RU\n\t\tif _, err := c.WriteString(\"\\n<h1>Привет & &lt;Открыть&gt;</h1>\"); err != nil {\n\t\t\treturn err\n\t\t}\n" +
+			"\t\tif _, err := c.WriteString(templates.EscapeHTML(t.i18n.GetText(t.payload.Key))); err != nil {",
+		"\t\treturn nil\n\t}\n\tif _, err := c.WriteString(\"\\n<h1>\"); err != nil {",
+		"c.WriteString(templates.EscapeHTML(templates.PGetText(t.i18n, \"menu\", \"<Open>\")))",
+		"func (t *Index_html) RenderBlock_menu(c templates.RenderContext) error {\n\tif _, err := c.WriteString(\"Menu\"); err != nil {",
+	} {
+		if !strings.Contains(code, s) {
+			t.Errorf("generated code does not contain:\n%s\n--- code:\n%s", s, code)
+		}
+	}
+}
+
+func TestCompileDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "strongo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	inputDir, outputDir := filepath.Join(dir, "templates"), filepath.Join(dir, "pages")
+	if err = os.MkdirAll(filepath.Join(inputDir, "folder"), 0775); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"index.html":         "Index",
+		"folder/header.html": "Header",
+		"readme.txt":         "Not a template",
+	}
+	for name, content := range files {
+		if err = ioutil.WriteFile(filepath.Join(inputDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := NewCodeGenerator(templates.StrongoEnvironment{})
+	if err = g.CompileDir(inputDir, outputDir); err != nil {
+		t.Fatal(err)
+	}
+	generated, err := ioutil.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range generated {
+		names = append(names, f.Name())
+	}
+	if strings.Join(names, ",") != "folder_header_html.go,index_html.go" {
+		t.Errorf("unexpected generated files: %v", names)
+	}
+	code, err := ioutil.ReadFile(filepath.Join(outputDir, "index_html.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "package pages\n") {
+		t.Errorf("package name should default to the output dir name, got:\n%s", code)
+	}
+}
+
+// goTool returns the path of the go command generated code is built with, skipping the test if there is none.
+func goTool(t *testing.T) string {
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command is not found:", err)
+	}
+	return goTool
+}
+
+// runGo runs the go command in the dir and returns its combined output.
+func runGo(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command(goTool(t), args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+func TestPrototype(t *testing.T) {
+	catalogs, err := templates.LoadCatalogs(filepath.Join("..", "..", "prototype", "locales"), "en_US", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	generatedDir := filepath.Join("..", "..", "prototype", "generated")
+	for _, test := range []struct {
+		dir       string
+		configure func(g *CodeGenerator)
+	}{
+		{generatedDir, func(g *CodeGenerator) {}},
+		{filepath.Join(generatedDir, "inlined"), func(g *CodeGenerator) {
+			g.Catalogs = catalogs
+			g.InlineLocales = []string{"en_US", "ru_RU"}
+		}},
+	} {
+		dir, err := ioutil.TempDir("", "strongo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		g := NewCodeGenerator(templates.StrongoEnvironment{})
+		g.PackageName = filepath.Base(test.dir)
+		test.configure(g)
+		if err = g.CompileDir(filepath.Join("..", "..", "prototype", "source_templates"), dir); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range g.Names() {
+			code, err := ioutil.ReadFile(filepath.Join(dir, GoFileName(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkedIn, err := ioutil.ReadFile(filepath.Join(test.dir, GoFileName(name)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(code, checkedIn) {
+				t.Errorf("%s is stale, run go generate in prototype/generated", filepath.Join(test.dir, GoFileName(name)))
+			}
+		}
+		runGo(t, test.dir, "vet", ".")
+	}
+}
+
+// mainTemplate is main.go of packages the end-to-end tests build from generated code.
+// Templates translate with catalogs and are rendered by render, printing the output.
+const mainTemplate = `package main
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/strongo/templates"
+)
+
+var catalogs = templates.NewCatalogs("en_US")
+
+func GetI18N(locale string) templates.I18n {
+	return catalogs.I18n(locale)
+}
+
+func render(template templates.Template) {
+	var b bytes.Buffer
+	if err := template.Render(templates.RenderContext{Writer: &b}); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	fmt.Println(b.String())
+}
+
+func main() {
+%s
+}
+`
+
+// buildTest is an end-to-end test: generated code of the templates and mainTemplate with the main body
+// are built into a program which is expected to print the output.
+type buildTest struct {
+	name      string
+	templates map[string]string
+	main      string
+	output    string
+}
+
+func (test buildTest) run(t *testing.T, g *CodeGenerator) {
+	goTool(t)
+	dir, err := ioutil.TempDir("", "strongo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	g.PackageName = "main"
+	for name, source := range test.templates {
+		if err = g.AddTemplate(name, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range g.Names() {
+		buffer := new(bytes.Buffer)
+		if err = g.Compile(name, buffer); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(filepath.Join(dir, GoFileName(name)), buffer.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	main := fmt.Sprintf(mainTemplate, test.main)
+	if err = ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGo(t, dir, "vet", ".")
+	if output := runGo(t, dir, "run", "."); output != test.output {
+		t.Errorf("%s: expected output:\n%s\n--- got:\n%s", test.name, test.output, output)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	catalogs := templates.NewCatalogs("en_US")
+	catalogs.Add("ru_RU", templates.Messages{"Hello": "Привет", "menu\x04<Open>": "<Открыть>"})
+
+	for _, test := range []struct {
+		buildTest
+		configure func(g *CodeGenerator)
+	}{
+		{buildTest{"inheritance", map[string]string{
+			"base.html":   `<title>{{ block title }}Site{{ endblock }}</title><body>{{ block body }}<p>Base</p>{{ endblock }}</body>`,
+			"layout.html": "@extends(\"base.html\")\n{{ block title }}{{ super() }} - Layout{{ endblock }}\n{{ block body }}<nav>{{ block menu }}Menu{{ endblock }}</nav>{{ block content }}{{ endblock }}{{ endblock }}",
+			"page.html":   "@extends(\"layout.html\")\n@params(name string)\n{{ block title }}{{ super() }} - {{ name }}{{ endblock }}\n{{ block content }}<h1>{{ name }}</h1>{{ endblock }}",
+		}, `render(NewPage_html("en_US", Payload_Page_html{Name: "<Me>"}))`,
+			"<title>Site - Layout - &lt;Me&gt;</title><body><nav>Menu</nav><h1>&lt;Me&gt;</h1></body>\n",
+		}, nil},
+		{buildTest{"includes", map[string]string{
+			"page.html":     "@params(name string)\n@include(\"greeting.html\", name=name, count=2)\n@include(\"greeting.html\", name=\"you\")",
+			"greeting.html": "@params(\n\tname string required\n\tcount int = 1\n)\n<p>Hi {{ name }} x{{ count }}</p>@include(\"sign.html\", who=name)",
+			"sign.html":     "@params(who string)\n<i>to {{ who }}</i>",
+		}, `render(NewPage_html("en_US", Payload_Page_html{Name: "<Me>"}))
+	render(NewPage_html("en_US", Payload_Page_html{}))`,
+			"\n\n<p>Hi &lt;Me&gt; x2</p>\n<i>to &lt;Me&gt;</i>\n\n<p>Hi you x1</p>\n<i>to you</i>\n" +
+				"error: greeting.html: required param \"name\" is missing\n",
+		}, nil},
+		{buildTest{"cache", map[string]string{
+			"page.html": "@params(name string)\n@cache(menu, ttl=\"1h\")\n<nav>{{ block menu }}{{ _(\"Menu\") }} {{ name }}{{ endblock }}</nav>",
+		}, `catalogs.Add("ru_RU", templates.Messages{"Menu": "Меню"})
+	render(NewPage_html("ru_RU", Payload_Page_html{Name: "Me"}))
+	catalogs.Add("ru_RU", templates.Messages{"Menu": "Навигация"})
+	render(NewPage_html("ru_RU", Payload_Page_html{Name: "Me"}))
+	render(NewPage_html("ru_RU", Payload_Page_html{Name: "You"}))
+	render(NewPage_html("en_US", Payload_Page_html{Name: "Me"}))`,
+			"\n\n<nav>Меню Me</nav>\n" +
+				"\n\n<nav>Меню Me</nav>\n" +
+				"\n\n<nav>Навигация You</nav>\n" +
+				"\n\n<nav>Menu Me</nav>\n",
+		}, nil},
+		{buildTest{"locales", map[string]string{
+			"page.html": "@params(name string)\n<h1>{{ _(\"Hello\") }}, {{ name }}!</h1><a>{{ _p(\"menu\", \"<Open>\") }}</a>",
+		}, `catalogs.Add("ru_RU", templates.Messages{"Hello": "Привет", "menu\x04<Open>": "<Открыть>"})
+	catalogs.Add("de", templates.Messages{"Hello": "Hallo"})
+	for _, locale := range []string{"en_US", "ru_RU", "de_DE", "fr_FR"} {
+		render(NewPage_html(locale, Payload_Page_html{Name: "<Me>"}))
+	}`,
+			"\n<h1>Hello, &lt;Me&gt;!</h1><a>&lt;Open&gt;</a>\n" +
+				"\n<h1>Привет, &lt;Me&gt;!</h1><a>&lt;Открыть&gt;</a>\n" +
+				"\n<h1>Hallo, &lt;Me&gt;!</h1><a>&lt;Open&gt;</a>\n" +
+				"\n<h1>Hello, &lt;Me&gt;!</h1><a>&lt;Open&gt;</a>\n",
+		}, func(g *CodeGenerator) {
+			g.Catalogs = catalogs
+			g.InlineLocales = []string{"en_US", "ru_RU"}
+		}},
+		{buildTest{"escapers", map[string]string{
+			"page.html": "@params(text string, url string, js string, color string, html templates.HTML)\n" +
+				`<a href="{{ url }}" title="{{ text }}" onclick="f({{ js }})">{{ text }}</a><a href="/search?q={{ text }}">Search</a>` + "\n" +
+				`<script>var s = "{{ text }}", v = {{ js }};</script><p style="color: {{ color }}">{{ html }}</p><img srcset="{{ url }} 2x">`,
+		}, `render(NewPage_html("en_US", Payload_Page_html{Text: "<\"&'>", Url: "javascript:alert(1)", Js: "</script>", Color: "expression(x)", Html: "<b>Safe</b>"}))
+	render(NewPage_html("en_US", Payload_Page_html{Text: "a b", Url: "/a.png?c=<d>", Js: "x", Color: "red", Html: templates.HTML("<br>")}))`,
+			"\n<a href=\"#ZgotmplZ\" title=\"&lt;&#34;&amp;&#39;&gt;\" onclick=\"f(&#34;\\u003c/script\\u003e&#34;)\">&lt;&#34;&amp;&#39;&gt;</a>" +
+				"<a href=\"/search?q=%3c%22%26%27%3e\">Search</a>\n" +
+				"<script>var s = \"\\x3c\\x22\\x26\\x27\\x3e\", v = \"\\u003c/script\\u003e\";</script>" +
+				"<p style=\"color: ZgotmplZ\"><b>Safe</b></p><img srcset=\"#ZgotmplZ 2x\">\n" +
+				"\n<a href=\"/a.png?c=%3cd%3e\" title=\"a b\" onclick=\"f(&#34;x&#34;)\">a b</a><a href=\"/search?q=a%20b\">Search</a>\n" +
+				"<script>var s = \"a b\", v = \"x\";</script><p style=\"color: red\"><br></p><img srcset=\"/a.png?c=%3cd%3e 2x\">\n",
+		}, nil},
+	} {
+		g := NewCodeGenerator(templates.StrongoEnvironment{})
+		if test.configure != nil {
+			test.configure(g)
+		}
+		test.run(t, g)
+	}
+}
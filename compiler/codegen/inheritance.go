@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"fmt"
+
+	"github.com/strongo/templates/parse"
+)
+
+// inheritance is the block table of a template resolved across its @extends chain.
+type inheritance struct {
+	chain  []*sourceTemplate                    // the template followed by the templates it extends, the root layout last
+	blocks []string                             // names of blocks available to the template, the ones declared by ancestors first
+	owners map[string]*sourceTemplate           // the nearest template in the chain defining content of a block
+	params []*parse.ParamNode                   // params of the template followed by params of ancestors it does not redeclare
+	owner  map[*parse.ParamNode]*sourceTemplate // the template declaring a param
+	caches map[string]*parse.CacheNode          // the nearest @cache of a block in the chain
+}
+
+// parent returns the template extended by the template, or nil.
+func (h *inheritance) parent() *sourceTemplate {
+	if len(h.chain) < 2 {
+		return nil
+	}
+	return h.chain[1]
+}
+
+// param returns a param by name, either own or inherited.
+func (h *inheritance) param(name string) *parse.ParamNode {
+	for _, param := range h.params {
+		if param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+// resolve walks the @extends chain of the template and merges blocks
+// overridden by each template into the block table of its parent.
+//
+// Blocks at the top level of an extending template must be declared by one of its ancestors;
+// blocks nested in them either override inherited blocks or declare new ones.
+// Blocks a template does not override fall back to the content of its parent.
+// A block is cached as the nearest template in the chain marking it with @cache says.
+func (g *CodeGenerator) resolve(t *sourceTemplate) (*inheritance, error) {
+	h := &inheritance{
+		owners: make(map[string]*sourceTemplate),
+		owner:  make(map[*parse.ParamNode]*sourceTemplate),
+		caches: make(map[string]*parse.CacheNode),
+	}
+	for current := t; ; {
+		h.chain = append(h.chain, current)
+		extends := current.tree.Extends
+		if extends == nil {
+			break
+		}
+		parent, ok := g.templates[extends.Path]
+		if !ok {
+			return nil, errorAt(current, extends, "template extends unknown template %q", extends.Path)
+		}
+		for _, child := range h.chain {
+			if child == parent {
+				return nil, errorAt(current, extends, "cyclic @extends of %q", extends.Path)
+			}
+		}
+		current = parent
+	}
+
+	for i := len(h.chain) - 1; i >= 0; i-- {
+		current := h.chain[i]
+		if current.tree.Extends != nil {
+			for _, node := range current.tree.Root.Nodes {
+				if block, ok := node.(*parse.BlockNode); ok && h.owners[block.Name] == nil {
+					return nil, errorAt(current, block, "block %q is not declared by %s", block.Name, current.tree.Extends.Path)
+				}
+			}
+		}
+		for _, block := range current.tree.Blocks {
+			if h.owners[block.Name] == nil {
+				h.blocks = append(h.blocks, block.Name)
+			}
+			h.owners[block.Name] = current
+		}
+		for _, cache := range current.tree.Caches {
+			if h.owners[cache.Block] == nil {
+				return nil, errorAt(current, cache, "@cache of unknown block %q", cache.Block)
+			}
+			h.caches[cache.Block] = cache
+		}
+	}
+
+	for _, current := range h.chain {
+		if current.tree.Params == nil {
+			continue
+		}
+		for _, param := range current.tree.Params.Params {
+			if redeclared := h.param(param.Name); redeclared != nil {
+				if redeclared.GoType != param.GoType {
+					return nil, errorAt(h.owner[redeclared], redeclared, "param %q of type %s conflicts with param of type %s declared by %s",
+						param.Name, redeclared.GoType, param.GoType, current.name)
+				}
+				continue
+			}
+			h.params = append(h.params, param)
+			h.owner[param] = current
+		}
+	}
+	return h, nil
+}
+
+// errorAt formats the error with location of the node in the template.
+func errorAt(t *sourceTemplate, node parse.Node, format string, args ...interface{}) error {
+	location, _ := t.tree.ErrorContext(node)
+	return fmt.Errorf("template: %s: %s", location, fmt.Sprintf(format, args...))
+}
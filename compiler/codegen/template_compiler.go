@@ -0,0 +1,1024 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"path"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/inspiration/builtin/html"
+	"github.com/strongo/templates/parse"
+)
+
+const templatesImport = "github.com/strongo/templates"
+
+// TemplateToGoCodeCompiler generates Go code for a single template.
+type TemplateToGoCodeCompiler struct {
+	g        *CodeGenerator
+	template *sourceTemplate
+
+	inheritance   *inheritance
+	receiverType  string // receiver type of the generated methods
+	blockReceiver string // receiver of RenderBlock_* calls: the template itself or the most derived one
+	block         string // name of the block being written, empty outside of blocks
+
+	buffer  bytes.Buffer
+	imports map[string]bool // import specs of the generated file, e.g. `"html"`
+	missing []string        // translations missing from catalogs, see CodeGenerator.TranslationCheck
+
+	locale  templates.I18n // translations inlined into the path being written; nil in the generic path
+	pending string         // static text not written yet, merged with inlined translations
+
+	context       html.Context            // context of the HTML parser at the node being written
+	blockContexts map[string]html.Context // contexts blocks start in, see blockContexts
+}
+
+// Compile writes formatted Go code of the template to the writer.
+func (c *TemplateToGoCodeCompiler) Compile(writer io.Writer) (err error) {
+	defer c.recover(&err)
+	if c.inheritance, err = c.g.resolve(c.template); err != nil {
+		return err
+	}
+	c.blockContexts = c.resolveBlockContexts()
+	c.imports = map[string]bool{`"context"`: true, strconv.Quote(templatesImport): true}
+
+	if len(c.g.InlineLocales) > 0 && c.g.Catalogs == nil {
+		return fmt.Errorf("template: %s: inlined locales need catalogs", c.template.name)
+	}
+	c.writeTemplate()
+	if len(c.missing) > 0 {
+		if c.g.TranslationCheck == FailOnMissingTranslations {
+			return fmt.Errorf("%s", strings.Join(c.missing, "\n"))
+		}
+		for _, missing := range c.missing {
+			fmt.Fprintln(c.g.report(), missing)
+		}
+	}
+
+	body := c.buffer.Bytes()
+	if err = c.addTemplateImports(body); err != nil {
+		return err
+	}
+	c.buffer = bytes.Buffer{}
+	c.writeHeader()
+	c.buffer.Write(body)
+
+	code, err := format.Source(c.buffer.Bytes())
+	if err != nil {
+		return fmt.Errorf("template: %s: failed to format generated code: %v", c.template.name, err)
+	}
+	_, err = writer.Write(code)
+	return err
+}
+
+// errorf formats the error with location of the node and terminates compilation.
+func (c *TemplateToGoCodeCompiler) errorf(node parse.Node, format string, args ...interface{}) {
+	location, _ := c.template.tree.ErrorContext(node)
+	panic(fmt.Errorf("template: %s: %s", location, fmt.Sprintf(format, args...)))
+}
+
+// recover is the handler that turns panics into returns from the top level of Compile.
+func (c *TemplateToGoCodeCompiler) recover(errp *error) {
+	if e := recover(); e != nil {
+		if _, ok := e.(runtime.Error); ok {
+			panic(e)
+		}
+		*errp = e.(error)
+	}
+}
+
+// writeLine writes a line of Go code; gofmt takes care of indentation.
+func (c *TemplateToGoCodeCompiler) writeLine(format string, args ...interface{}) {
+	fmt.Fprintf(&c.buffer, format, args...)
+	c.buffer.WriteByte('\n')
+}
+
+func (c *TemplateToGoCodeCompiler) typeName() string {
+	return TypeName(c.template.name)
+}
+
+func (c *TemplateToGoCodeCompiler) payloadTypeName() string {
+	return "Payload_" + c.typeName()
+}
+
+// addTemplateImports adds the @import specs of the template the generated code refers to;
+// unused ones would fail the build of the generated package.
+func (c *TemplateToGoCodeCompiler) addTemplateImports(body []byte) error {
+	if len(c.template.tree.Imports) == 0 {
+		return nil
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "", append([]byte("package p\n"), body...), 0)
+	if err != nil {
+		return fmt.Errorf("template: %s: failed to parse generated code: %v", c.template.name, err)
+	}
+	used := make(map[string]bool)
+	ast.Inspect(f, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if x, ok := selector.X.(*ast.Ident); ok {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+	for _, spec := range c.template.tree.Imports {
+		if spec.Name == "." || spec.Name == "_" || used[importName(spec)] {
+			c.imports[spec.String()] = true
+		}
+	}
+	return nil
+}
+
+// importName returns the name code refers to the imported package by: its local name or,
+// as goimports guesses it, the last element of the path without a major version suffix, e.g. "yaml" for "gopkg.in/yaml.v2".
+func importName(spec *parse.ImportNode) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	elements := strings.Split(spec.Path, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && majorVersion.MatchString(name) {
+		name = elements[len(elements)-2]
+	}
+	name = strings.TrimPrefix(strings.TrimSuffix(name, "-go"), "go-")
+	if i := strings.IndexAny(name, ".-"); i > 0 {
+		name = name[:i]
+	}
+	return name
+}
+
+var majorVersion = regexp.MustCompile(`^v[0-9]+$`)
+
+func (c *TemplateToGoCodeCompiler) writeHeader() {
+	c.writeLine("// Code generated by strongo from %s. DO NOT EDIT.", c.template.name)
+	c.writeLine("")
+	c.writeLine("package %s", c.g.PackageName)
+	c.writeLine("")
+	var std, other []string // standard library imports go first
+	for spec := range c.imports {
+		fields := strings.Fields(spec)
+		if importPath, _ := strconv.Unquote(fields[len(fields)-1]); strings.Contains(strings.Split(importPath, "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	c.writeLine("import (")
+	for _, spec := range std {
+		c.writeLine("%s", spec)
+	}
+	c.writeLine("")
+	for _, spec := range other {
+		c.writeLine("%s", spec)
+	}
+	c.writeLine(")")
+	c.writeLine("")
+}
+
+func (c *TemplateToGoCodeCompiler) writeTemplate() {
+	t := c.template
+	typeName := c.typeName()
+
+	c.writeLine("const source_%s = %s", typeName, strconv.Quote(t.source))
+	c.writeLine("")
+
+	c.writePayload()
+
+	if c.g.isExtended(t.name) {
+		c.writeLayout()
+	} else {
+		c.writePage()
+	}
+
+	for _, name := range c.inheritance.blocks {
+		c.writeLine("")
+		method := "RenderBlock_" + name
+		if cache := c.inheritance.caches[name]; cache != nil && !c.g.isExtended(t.name) {
+			// Layouts render blocks of pages, so pages are the ones caching them.
+			method = "renderBlock_" + name
+			c.writeCachedBlock(name, cache.TTL, method)
+		}
+		c.writeLine("func (t %s) %s(c templates.RenderContext) error {", c.receiverType, method)
+		if c.inheritance.owners[name] != t {
+			// Not overridden, falls back to the content of the parent.
+			c.writeLine("return t.extends.RenderBlock_%s(c)", name)
+			c.writeLine("}")
+			continue
+		}
+		c.block = name
+		block, start := t.tree.Block(name), c.blockContexts[name]
+		c.writeBody(block.List, start)
+		if !c.context.Equal(start) {
+			c.errorf(block, "block %q ends in context %s, not in %s it starts in", name, c.context, start)
+		}
+		c.block = ""
+		c.writeLine("}")
+	}
+}
+
+// writeCachedBlock writes the RenderBlock_* method of a block marked with @cache
+// rendering it with the method unless it is in templates.Fragments.
+// The lookup happens on render, so GetData of the template runs on a hit too.
+func (c *TemplateToGoCodeCompiler) writeCachedBlock(name string, ttl time.Duration, method string) {
+	c.writeLine("func (t %s) RenderBlock_%s(c templates.RenderContext) error {", c.receiverType, name)
+	c.writeLine("key := templates.FragmentKey{")
+	c.writeLine("Template: %s,", strconv.Quote(c.template.name))
+	c.writeLine("Block: %s,", strconv.Quote(name))
+	c.writeLine("Payload: templates.PayloadHash(t.payload),")
+	c.writeLine("Locale: templates.LocaleOf(t.i18n),")
+	c.writeLine("}")
+	c.writeLine("return templates.Fragments.Render(c, key, %s, t.%s)", c.duration(ttl), method)
+	c.writeLine("}")
+	c.writeLine("")
+}
+
+// duration returns Go code of the duration.
+func (c *TemplateToGoCodeCompiler) duration(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	c.imports[`"time"`] = true
+	for _, unit := range []struct {
+		d    time.Duration
+		code string
+	}{{time.Hour, "time.Hour"}, {time.Minute, "time.Minute"}, {time.Second, "time.Second"}, {time.Millisecond, "time.Millisecond"}} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d*%s", d/unit.d, unit.code)
+		}
+	}
+	return fmt.Sprintf("time.Duration(%d)", d)
+}
+
+// writePage writes the template type rendered by the application.
+func (c *TemplateToGoCodeCompiler) writePage() {
+	t := c.template
+	typeName, payloadTypeName := c.typeName(), c.payloadTypeName()
+	c.receiverType, c.blockReceiver = "*"+typeName, "t"
+
+	c.writeLine("// %s renders %s.", typeName, t.name)
+	c.writeLine("type %s struct {", typeName)
+	c.writeLine("i18n templates.I18n")
+	c.writeLocaleField()
+	c.writeLine("payload %s", payloadTypeName)
+	c.writeLine("err error // Payload error returned by GetData and Render.")
+	if parent := c.inheritance.parent(); parent != nil {
+		c.writeLine("extends %s", layoutTypeName(parent.name))
+	}
+	c.writeLine("}")
+	c.writeLine("")
+
+	c.writeLine("// New%s creates %s template for the locale.", typeName, t.name)
+	c.writeLine("func New%s(locale string, payload %s) templates.Template {", typeName, payloadTypeName)
+	c.writeLine("return new%s(%s(locale), payload)", typeName, c.g.i18nFunc())
+	c.writeLine("}")
+	c.writeLine("")
+	c.writeLine("func new%s(i18n templates.I18n, payload %s) *%s {", typeName, payloadTypeName, typeName)
+	c.writeLine("payload, err := New%s(payload)", payloadTypeName)
+	if c.inheritance.parent() == nil {
+		c.writeLine("return &%s{", typeName)
+		c.writeLine("i18n: i18n,")
+		c.writeLocale()
+		c.writeLine("payload: payload,")
+		c.writeLine("err: err,")
+		c.writeLine("}")
+	} else {
+		c.writeLine("t := &%s{", typeName)
+		c.writeLine("i18n: i18n,")
+		c.writeLocale()
+		c.writeLine("payload: payload,")
+		c.writeLine("err: err,")
+		c.writeLine("}")
+		c.writeExtends("t")
+		c.writeLine("return t")
+	}
+	c.writeLine("}")
+	c.writeLine("")
+
+	c.writeLine("func (t *%s) Name() string {", typeName)
+	c.writeLine("return %s", strconv.Quote(path.Base(t.name)))
+	c.writeLine("}")
+	c.writeLine("")
+	c.writeLine("func (t *%s) Path() string {", typeName)
+	c.writeLine("return %s", strconv.Quote(t.name))
+	c.writeLine("}")
+	c.writeLine("")
+	c.writeLine("func (t *%s) Source() string {", typeName)
+	c.writeLine("return source_%s", typeName)
+	c.writeLine("}")
+	c.writeLine("")
+	c.writeGetData()
+	c.writeRender()
+}
+
+// writeLayout writes the types of a template extended by other templates:
+// the interface extending templates implement and the struct
+// rendering the layout with blocks of the most derived template.
+func (c *TemplateToGoCodeCompiler) writeLayout() {
+	t := c.template
+	typeName, payloadTypeName := c.typeName(), c.payloadTypeName()
+	structName := layoutTypeName(t.name)
+	c.receiverType, c.blockReceiver = structName, "t.template"
+
+	c.writeLine("// %s is implemented by templates extending %s.", typeName, t.name)
+	c.writeLine("type %s interface {", typeName)
+	for _, name := range c.inheritance.blocks {
+		c.writeLine("RenderBlock_%s(c templates.RenderContext) error", name)
+	}
+	c.writeLine("}")
+	c.writeLine("")
+
+	c.writeLine("// %s renders %s with blocks of the extending template.", structName, t.name)
+	c.writeLine("type %s struct {", structName)
+	c.writeLine("i18n templates.I18n")
+	c.writeLocaleField()
+	c.writeLine("template %s // The most derived template; blocks are rendered by it.", typeName)
+	c.writeLine("payload %s", payloadTypeName)
+	c.writeLine("err error // Payload error returned by GetData and Render.")
+	if parent := c.inheritance.parent(); parent != nil {
+		c.writeLine("extends %s", layoutTypeName(parent.name))
+	}
+	c.writeLine("}")
+	c.writeLine("")
+
+	c.writeLine("// New_%s creates %s for the extending template.", structName, t.name)
+	c.writeLine("func New_%s(i18n templates.I18n, template %s, payload %s) %s {", structName, typeName, payloadTypeName, structName)
+	c.writeLine("payload, err := New%s(payload)", payloadTypeName)
+	c.writeLine("t := %s{", structName)
+	c.writeLine("i18n: i18n,")
+	c.writeLocale()
+	c.writeLine("template: template,")
+	c.writeLine("payload: payload,")
+	c.writeLine("err: err,")
+	c.writeLine("}")
+	if c.inheritance.parent() != nil {
+		c.writeExtends("template")
+	}
+	c.writeLine("return t")
+	c.writeLine("}")
+	c.writeLine("")
+	c.writeGetData()
+	c.writeRender()
+}
+
+// writeLocaleField writes the field selecting the render path with inlined translations, see writeBody.
+func (c *TemplateToGoCodeCompiler) writeLocaleField() {
+	if len(c.g.InlineLocales) > 0 {
+		c.writeLine("locale int // 1 + index of the locale in inlined ones, 0 if its translations are not inlined.")
+	}
+}
+
+func (c *TemplateToGoCodeCompiler) writeLocale() {
+	if len(c.g.InlineLocales) > 0 {
+		locales := make([]string, len(c.g.InlineLocales))
+		for i, locale := range c.g.InlineLocales {
+			locales[i] = strconv.Quote(locale)
+		}
+		c.writeLine("locale: templates.LocaleIndex(i18n, %s),", strings.Join(locales, ", "))
+	}
+}
+
+// writeExtends writes code creating the parent layout rendering blocks of the template.
+func (c *TemplateToGoCodeCompiler) writeExtends(template string) {
+	parent := c.inheritance.parent()
+	c.writeLine("t.extends = New_%s(i18n, %s, Payload_%s{", layoutTypeName(parent.name), template, TypeName(parent.name))
+	for _, param := range c.parentInheritance().params {
+		c.writeLine("%s: payload.%s,", exportedName(param.Name), exportedName(param.Name))
+	}
+	c.writeLine("})")
+}
+
+// parentInheritance returns the resolved block table of the template's parent.
+func (c *TemplateToGoCodeCompiler) parentInheritance() *inheritance {
+	h, err := c.g.resolve(c.inheritance.parent())
+	if err != nil {
+		panic(err) // Not reachable: the chain is resolved with the template.
+	}
+	return h
+}
+
+func (c *TemplateToGoCodeCompiler) writeGetData() {
+	c.writeLine("func (t %s) GetData(ctx context.Context) error {", c.receiverType)
+	if c.inheritance.parent() == nil {
+		c.writeLine("return t.err")
+	} else {
+		c.writeLine("if t.err != nil {")
+		c.writeLine("return t.err")
+		c.writeLine("}")
+		c.writeLine("return t.extends.GetData(ctx)")
+	}
+	c.writeLine("}")
+	c.writeLine("")
+}
+
+// writeRender writes the render method; extending templates are rendered by the root layout.
+// Pages get the exported Render finishing the out-of-order rendering,
+// templates including them call render.
+func (c *TemplateToGoCodeCompiler) writeRender() {
+	method := "Render"
+	if !c.g.isExtended(c.template.name) {
+		method = "render"
+		c.writeLine("func (t %s) Render(c templates.RenderContext) error {", c.receiverType)
+		c.writeLine("if err := t.render(c); err != nil {")
+		c.writeLine("return err")
+		c.writeLine("}")
+		c.writeLine("return c.RenderPending()")
+		c.writeLine("}")
+		c.writeLine("")
+	}
+	c.writeLine("func (t %s) %s(c templates.RenderContext) error {", c.receiverType, method)
+	c.writeLine("if t.err != nil {")
+	c.writeLine("return t.err")
+	c.writeLine("}")
+	if c.inheritance.parent() != nil {
+		c.writeLine("return t.extends.Render(c)")
+	} else {
+		root := c.template.tree.Root
+		c.writeBody(root, html.Context{})
+		if !c.context.IsText() {
+			c.errorf(root.Nodes[len(root.Nodes)-1], "template ends in a non-text context: %s", c.context)
+		}
+	}
+	c.writeLine("}")
+}
+
+// writePayload writes the payload struct and its constructor
+// that checks required params and applies defaults of optional ones.
+func (c *TemplateToGoCodeCompiler) writePayload() {
+	t := c.template
+	payloadTypeName := c.payloadTypeName()
+	var params []*parse.ParamNode
+	if t.tree.Params != nil {
+		params = t.tree.Params.Params
+	}
+
+	// Params of the templates it extends are passed to them, the template checks its own ones.
+	c.writeLine("// %s holds params of %s.", payloadTypeName, t.name)
+	c.writeLine("type %s struct {", payloadTypeName)
+	for _, param := range c.inheritance.params {
+		if param.Required {
+			c.writeLine("%s %s // required", exportedName(param.Name), param.GoType)
+		} else {
+			c.writeLine("%s %s", exportedName(param.Name), param.GoType)
+		}
+	}
+	c.writeLine("}")
+	c.writeLine("")
+
+	c.writeLine("// New%s checks required params are set and applies defaults to optional params left with zero values.", payloadTypeName)
+	c.writeLine("func New%s(payload %s) (%s, error) {", payloadTypeName, payloadTypeName, payloadTypeName)
+	for _, param := range params {
+		field := "payload." + exportedName(param.Name)
+		switch {
+		case param.Required:
+			if param.GoType == "bool" {
+				c.errorf(param, "bool param %q can not be required as false is its zero value", param.Name)
+			}
+			c.writeLine("if %s {", c.isZero(field, param.GoType))
+			c.writeLine("return payload, templates.MissingParamError{Template: %s, Param: %s}", strconv.Quote(t.name), strconv.Quote(param.Name))
+			c.writeLine("}")
+		case param.Default != nil:
+			c.checkDefault(param)
+			if param.Default.Type() == parse.NodeNil {
+				continue // Nil is the zero value already.
+			}
+			c.writeLine("if %s {", c.isZero(field, param.GoType))
+			c.writeLine("%s = %s", field, param.Default)
+			c.writeLine("}")
+		}
+	}
+	c.writeLine("return payload, nil")
+	c.writeLine("}")
+	c.writeLine("")
+}
+
+// isZero returns condition checking the expression has zero value of the Go type.
+func (c *TemplateToGoCodeCompiler) isZero(code, goType string) string {
+	switch {
+	case goType == "string":
+		return code + ` == ""`
+	case goType == "bool":
+		return "!" + code
+	case isNumeric(goType):
+		return code + " == 0"
+	case isNillable(goType):
+		return code + " == nil"
+	}
+	return "templates.IsZero(" + code + ")"
+}
+
+// checkDefault reports default values that can not be assigned to the param.
+func (c *TemplateToGoCodeCompiler) checkDefault(param *parse.ParamNode) {
+	if value, ok := param.Default.(*parse.BoolNode); ok && value.True && param.GoType == "bool" {
+		c.errorf(param, "bool param %q can not default to true as false is its zero value", param.Name)
+	}
+	if !assignable(param.Default, param.GoType) {
+		c.errorf(param, "cannot use %s as %s value in default of param %q", param.Default, param.GoType, param.Name)
+	}
+}
+
+// assignable reports whether the literal can be assigned to a value of the Go type.
+// Named types are not known at compile time and are left to the Go compiler.
+func assignable(value parse.Node, goType string) bool {
+	if !isBuiltin(goType) && !isNillable(goType) {
+		return true
+	}
+	switch value := value.(type) {
+	case *parse.StringNode:
+		return goType == "string"
+	case *parse.NumberNode:
+		return isFloat(goType) || isNumeric(goType) && value.IsInt
+	case *parse.BoolNode:
+		return goType == "bool"
+	case *parse.NilNode:
+		return isNillable(goType)
+	}
+	return false
+}
+
+// writeBody writes statements of a render method rendering the list starting in the context.
+// If the list has translations of static keys and locales are inlined,
+// a path with the translations merged into static text is written for each of the locales first.
+func (c *TemplateToGoCodeCompiler) writeBody(list *parse.ListNode, start html.Context) {
+	if len(c.g.InlineLocales) > 0 && c.hasStaticTranslation(list) {
+		c.writeLine("switch t.locale {")
+		for i, locale := range c.g.InlineLocales {
+			c.writeLine("case %d: // %s", i+1, locale)
+			c.locale, c.context = c.g.Catalogs.I18n(locale), start
+			c.writeList(list)
+			c.flush()
+			c.writeLine("return nil")
+		}
+		c.locale = nil
+		c.writeLine("}")
+	}
+	c.context = start
+	c.writeList(list)
+	c.writeLine("return nil")
+}
+
+func (c *TemplateToGoCodeCompiler) hasStaticTranslation(list *parse.ListNode) bool {
+	for _, node := range list.Nodes {
+		if action, ok := node.(*parse.ActionNode); ok && staticTranslation(action.Expr) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// staticTranslation returns the call if it translates a literal key without arguments, e.g. _("Hi") or _p("menu", "Open").
+func staticTranslation(node parse.Node) *parse.CallNode {
+	call, ok := node.(*parse.CallNode)
+	if !ok || len(call.Named) != 0 || call.Func.Ident != "_" && call.Func.Ident != "_p" {
+		return nil
+	}
+	if len(call.Args) != map[string]int{"_": 1, "_p": 2}[call.Func.Ident] {
+		return nil
+	}
+	for _, arg := range call.Args {
+		if _, ok := arg.(*parse.StringNode); !ok {
+			return nil
+		}
+	}
+	return call
+}
+
+// inline returns the translation of the static key to the locale being inlined passed through the escapers.
+func (c *TemplateToGoCodeCompiler) inline(call *parse.CallNode, escapers []string) string {
+	var text string
+	if call.Func.Ident == "_p" {
+		text = templates.PGetText(c.locale, call.Args[0].(*parse.StringNode).Text, call.Args[1].(*parse.StringNode).Text)
+	} else {
+		text = c.locale.GetText(call.Args[0].(*parse.StringNode).Text)
+	}
+	for _, escaper := range escapers {
+		text = html.Escapers[escaper](text)
+	}
+	return text
+}
+
+// flush writes the static text pending in the inlined path.
+func (c *TemplateToGoCodeCompiler) flush() {
+	if c.pending != "" {
+		pending := c.pending
+		c.pending = ""
+		c.writeString(strconv.Quote(pending))
+	}
+}
+
+func (c *TemplateToGoCodeCompiler) writeList(list *parse.ListNode) {
+	for _, node := range list.Nodes {
+		c.writeNode(node)
+	}
+}
+
+func (c *TemplateToGoCodeCompiler) writeNode(node parse.Node) {
+	if c.locale != nil {
+		switch node := node.(type) {
+		case *parse.TextNode:
+			c.text(node)
+			c.pending += string(node.Text)
+			return
+		case *parse.ActionNode:
+			if call := staticTranslation(node.Expr); call != nil {
+				c.pending += c.inline(call, c.action(node))
+				return
+			}
+		}
+		c.flush()
+	}
+	switch node := node.(type) {
+	case *parse.TextNode:
+		c.text(node)
+		c.writeString(strconv.Quote(string(node.Text)))
+	case *parse.ActionNode:
+		if call, ok := node.Expr.(*parse.CallNode); ok && call.Func.Ident == "super" {
+			c.writeSuper(call)
+			break
+		}
+		c.writeString(c.output(node.Expr, c.action(node)))
+	case *parse.IncludeNode:
+		if !c.context.IsText() {
+			c.errorf(node, "@include in a non-text context: %s", c.context)
+		}
+		c.writeInclude(node)
+	case *parse.BlockNode:
+		if start := c.blockContexts[node.Name]; !c.context.Equal(start) {
+			c.errorf(node, "block %q is in context %s, not in %s it is declared in", node.Name, c.context, start)
+		}
+		c.writeLine("if err := %s.RenderBlock_%s(c); err != nil {", c.blockReceiver, node.Name)
+		c.writeLine("return err")
+		c.writeLine("}")
+	default:
+		c.errorf(node, "unexpected %s", node)
+	}
+}
+
+// writeSuper writes code rendering content the parent template has for the block being written.
+// The parent falls back to its own parent if it does not override the block, so it works at any depth.
+func (c *TemplateToGoCodeCompiler) writeSuper(call *parse.CallNode) {
+	if n := len(call.Args) + len(call.Named); n != 0 {
+		c.errorf(call, "super expects no arguments, got %d", n)
+	}
+	if c.block == "" {
+		c.errorf(call, "super() outside of block")
+	}
+	if c.inheritance.parent() == nil || c.parentInheritance().owners[c.block] == nil {
+		c.errorf(call, "super() in block %q that is not declared by a parent template", c.block)
+	}
+	c.writeLine("if err := t.extends.RenderBlock_%s(c); err != nil {", c.block)
+	c.writeLine("return err")
+	c.writeLine("}")
+}
+
+// writeInclude writes code rendering the included template with the shared render context.
+// Arguments are checked against params of the included template.
+func (c *TemplateToGoCodeCompiler) writeInclude(node *parse.IncludeNode) {
+	included, ok := c.g.templates[node.Path]
+	if !ok {
+		c.errorf(node, "include of unknown template %q", node.Path)
+	}
+	if c.g.isExtended(included.name) {
+		c.errorf(node, "can not include %s as it is extended by other templates", included.name)
+	}
+	h, err := c.g.resolve(included)
+	if err != nil {
+		panic(err)
+	}
+	var fields []string
+	for _, arg := range node.Args {
+		param := h.param(arg.Name)
+		if param == nil {
+			c.errorf(arg, "unknown param %q of %s", arg.Name, included.name)
+		}
+		fields = append(fields, exportedName(param.Name)+": "+c.argument(arg, param))
+	}
+	for _, param := range h.params {
+		if param.Required && node.Arg(param.Name) == nil {
+			c.errorf(node, "missing required param %q of %s", param.Name, included.name)
+		}
+	}
+	typeName := TypeName(included.name)
+	c.writeLine("if err := new%s(t.i18n, Payload_%s{%s}).render(c); err != nil {", typeName, typeName, strings.Join(fields, ", "))
+	c.writeLine("return err")
+	c.writeLine("}")
+}
+
+// argument returns code of the value passed to the param of an included template.
+func (c *TemplateToGoCodeCompiler) argument(arg *parse.ArgNode, param *parse.ParamNode) string {
+	switch value := arg.Value.(type) {
+	case *parse.StringNode, *parse.NumberNode, *parse.BoolNode, *parse.NilNode:
+		if !assignable(value, param.GoType) {
+			c.errorf(arg, "cannot use %s as %s value in argument %q", value, param.GoType, arg.Name)
+		}
+		return value.String()
+	}
+	code, goType := c.expression(arg.Value)
+	if chain, ok := arg.Value.(*parse.ChainNode); ok {
+		goType = c.chainType(chain)
+	}
+	if goType != param.GoType {
+		c.errorf(arg, "cannot use %s (type %s) as %s value in argument %q", arg.Value, goType, param.GoType, arg.Name)
+	}
+	return code
+}
+
+// chainType returns the Go type of the field the chain selects, looked up in the package of the type
+// of the param the chain starts with, as it is imported by the template declaring the param.
+func (c *TemplateToGoCodeCompiler) chainType(chain *parse.ChainNode) string {
+	ident, ok := chain.Node.(*parse.IdentifierNode)
+	if !ok {
+		c.errorf(chain, "cannot resolve type of %s", chain)
+	}
+	param := c.inheritance.param(ident.Ident)
+	if param == nil {
+		c.errorf(ident, "undefined: %s", ident.Ident)
+	}
+	expr, err := parser.ParseExpr(param.GoType)
+	if err != nil {
+		c.errorf(chain, "cannot resolve type of %s: %v", chain, err)
+	}
+	typ := c.resolveType(chain, expr, c.inheritance.owner[param])
+	for _, field := range chain.Field {
+		object, _, _ := types.LookupFieldOrMethod(typ, true, nil, field)
+		v, ok := object.(*types.Var)
+		if !ok {
+			c.errorf(chain, "cannot resolve type of %s: %s has no field %s", chain, typ, field)
+		}
+		typ = v.Type()
+	}
+	return types.TypeString(typ, func(p *types.Package) string { return p.Name() })
+}
+
+// resolveType returns the type of the type expression of a param declared by the template.
+// Named types have to be qualified by packages the template imports.
+func (c *TemplateToGoCodeCompiler) resolveType(chain *parse.ChainNode, expr ast.Expr, t *sourceTemplate) types.Type {
+	switch expr := expr.(type) {
+	case *ast.StarExpr:
+		return types.NewPointer(c.resolveType(chain, expr.X, t))
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			for _, spec := range t.tree.Imports {
+				if importName(spec) != x.Name {
+					continue
+				}
+				pkg, err := c.g.importer().Import(spec.Path)
+				if err != nil {
+					c.errorf(chain, "cannot resolve type of %s: %v", chain, err)
+				}
+				if object, ok := pkg.Scope().Lookup(expr.Sel.Name).(*types.TypeName); ok {
+					return object.Type()
+				}
+			}
+		}
+	}
+	c.errorf(chain, "cannot resolve type of %s: param type %s is not a type of an imported package", chain, types.ExprString(expr))
+	return nil
+}
+
+// writeString writes code that writes the string expression to the render context.
+func (c *TemplateToGoCodeCompiler) writeString(code string) {
+	c.writeLine("if _, err := c.WriteString(%s); err != nil {", code)
+	c.writeLine("return err")
+	c.writeLine("}")
+}
+
+// output returns code of the string value of the expression passed through the escapers, see templates.EscapeHTML.
+// Values of a safe content type are written as they are where the context matches the type.
+func (c *TemplateToGoCodeCompiler) output(node parse.Node, escapers []string) string {
+	code, goType := c.expression(node)
+	if len(escapers) > 0 && safeContentEscapers[goType] == escapers[0] {
+		if escapers = escapers[1:]; len(escapers) == 0 {
+			return "string(" + code + ")"
+		}
+	}
+	if len(escapers) == 0 && goType != "string" {
+		c.imports[`"fmt"`] = true
+		return "fmt.Sprint(" + code + ")"
+	}
+	for _, escaper := range escapers {
+		code = "templates." + escaper + "(" + code + ")"
+	}
+	return code
+}
+
+// safeContentEscapers maps safe content types, see templates.HTML, to the escapers passing their values through.
+var safeContentEscapers = map[string]string{
+	"templates.HTML":   "EscapeHTML",
+	"templates.JS":     "EscapeJSVal",
+	"templates.CSS":    "FilterCSSValue",
+	"templates.URL":    "FilterURL",
+	"templates.Srcset": "EscapeSrcset",
+}
+
+// text moves the context past the static text.
+func (c *TemplateToGoCodeCompiler) text(node *parse.TextNode) {
+	c.context = c.context.AfterText(node.Text)
+	if err := c.context.Err(); err != nil {
+		c.errorf(node, "%s", err.Description)
+	}
+}
+
+// action moves the context past the value the action prints and returns escapers of the value.
+func (c *TemplateToGoCodeCompiler) action(node *parse.ActionNode) []string {
+	context, escapers := c.context.Action()
+	if err := context.Err(); err != nil {
+		c.errorf(node, "%s", err.Description)
+	}
+	c.context = context
+	return escapers
+}
+
+// resolveBlockContexts returns the contexts blocks of the template start in:
+// the ones of the text around the first declaration of each block, walking the @extends chain from the root layout.
+// Errors are left to compilation of the templates declaring the blocks.
+func (c *TemplateToGoCodeCompiler) resolveBlockContexts() map[string]html.Context {
+	contexts := make(map[string]html.Context)
+	var walk func(context html.Context, list *parse.ListNode) html.Context
+	walk = func(context html.Context, list *parse.ListNode) html.Context {
+		for _, node := range list.Nodes {
+			switch node := node.(type) {
+			case *parse.TextNode:
+				context = context.AfterText(node.Text)
+			case *parse.ActionNode:
+				if call, ok := node.Expr.(*parse.CallNode); !ok || call.Func.Ident != "super" {
+					context, _ = context.Action()
+				}
+			case *parse.BlockNode:
+				if _, ok := contexts[node.Name]; !ok {
+					contexts[node.Name] = context
+				}
+				walk(contexts[node.Name], node.List)
+			}
+		}
+		return context
+	}
+	for i := len(c.inheritance.chain) - 1; i >= 0; i-- {
+		walk(html.Context{}, c.inheritance.chain[i].tree.Root)
+	}
+	return contexts
+}
+
+// expression returns code evaluating the node and its Go type.
+// The type is empty if it is not known at compile time.
+func (c *TemplateToGoCodeCompiler) expression(node parse.Node) (code, goType string) {
+	switch node := node.(type) {
+	case *parse.IdentifierNode:
+		param := c.inheritance.param(node.Ident)
+		if param == nil {
+			c.errorf(node, "undefined: %s", node.Ident)
+		}
+		return "t.payload." + exportedName(param.Name), param.GoType
+	case *parse.ChainNode:
+		code, _ = c.expression(node.Node)
+		return code + "." + strings.Join(node.Field, "."), ""
+	case *parse.StringNode:
+		return node.Quoted, "string"
+	case *parse.NumberNode:
+		if node.IsInt {
+			return node.Text, "int"
+		}
+		return node.Text, "float64"
+	case *parse.CallNode:
+		return c.call(node)
+	}
+	c.errorf(node, "unexpected %s in expression", node)
+	return
+}
+
+// call returns code of the call to a template function:
+//	_(key[, name=value ...]) translates the key filling its named placeholders
+//	_n(singular, plural, n[, name=value ...]) translates the plural form for n
+//	_p(context, key[, name=value ...]) and _np(context, singular, plural, n[, name=value ...]) translate in the context
+func (c *TemplateToGoCodeCompiler) call(node *parse.CallNode) (code, goType string) {
+	switch node.Func.Ident {
+	case "_", "_n", "_p", "_np":
+		if c.locale == nil { // checked once, in the generic path
+			c.checkTranslation(node)
+		}
+	}
+	switch node.Func.Ident {
+	case "_":
+		if len(node.Args) != 1 {
+			c.errorf(node, "_ expects 1 argument, got %d", len(node.Args))
+		}
+		return c.format(node, "t.i18n.GetText("+c.message(node, node.Args[0])+")"), "string"
+	case "_n":
+		if len(node.Args) != 3 {
+			c.errorf(node, "_n expects 3 arguments, got %d", len(node.Args))
+		}
+		return c.format(node, "t.i18n.NGetText("+c.message(node, node.Args[0])+", "+c.message(node, node.Args[1])+", "+c.count(node, node.Args[2])+")"), "string"
+	case "_p":
+		if len(node.Args) != 2 {
+			c.errorf(node, "_p expects 2 arguments, got %d", len(node.Args))
+		}
+		return c.format(node, "templates.PGetText(t.i18n, "+c.message(node, node.Args[0])+", "+c.message(node, node.Args[1])+")"), "string"
+	case "_np":
+		if len(node.Args) != 4 {
+			c.errorf(node, "_np expects 4 arguments, got %d", len(node.Args))
+		}
+		return c.format(node, "templates.NPGetText(t.i18n, "+c.message(node, node.Args[0])+", "+c.message(node, node.Args[1])+", "+
+			c.message(node, node.Args[2])+", "+c.count(node, node.Args[3])+")"), "string"
+	case "super":
+		c.errorf(node, "super() can only be used as an action, e.g. {{ super() }}")
+	}
+	c.errorf(node, "undefined function: %s", node.Func.Ident)
+	return
+}
+
+// checkTranslation records locales lacking a translation of the key of the call with literal strings,
+// see CodeGenerator.TranslationCheck.
+func (c *TemplateToGoCodeCompiler) checkTranslation(call *parse.CallNode) {
+	if c.g.Catalogs == nil || c.g.TranslationCheck == IgnoreMissingTranslations || len(call.Args) == 0 {
+		return
+	}
+	args := call.Args
+	context := ""
+	if call.Func.Ident == "_p" || call.Func.Ident == "_np" {
+		s, ok := args[0].(*parse.StringNode)
+		if !ok || len(args) < 2 {
+			return
+		}
+		context, args = s.Text, args[1:]
+	}
+	key, ok := args[0].(*parse.StringNode)
+	if !ok {
+		return
+	}
+	if locales := c.g.missingTranslations(context, key.Text); len(locales) > 0 {
+		location, _ := c.template.tree.ErrorContext(call)
+		c.missing = append(c.missing, fmt.Sprintf("template: %s: %s has no translation to %s", location, key.Quoted, strings.Join(locales, ", ")))
+	}
+}
+
+// message returns code of a message argument of a translation function.
+func (c *TemplateToGoCodeCompiler) message(call *parse.CallNode, arg parse.Node) string {
+	code, goType := c.expression(arg)
+	if goType != "string" && goType != "" {
+		c.errorf(call, "cannot use %s (type %s) as translation key", arg, goType)
+	}
+	return code
+}
+
+// count returns code of the plural count argument of a translation function converted to int.
+func (c *TemplateToGoCodeCompiler) count(call *parse.CallNode, arg parse.Node) string {
+	n, nType := c.expression(arg)
+	switch {
+	case nType == "int":
+	case nType == "" || isNumeric(nType) && !isFloat(nType):
+		n = "int(" + n + ")"
+	default:
+		c.errorf(call, "cannot use %s (type %s) as plural count", arg, nType)
+	}
+	return n
+}
+
+// format returns code filling named placeholders of the translated message with named arguments of the call.
+func (c *TemplateToGoCodeCompiler) format(call *parse.CallNode, message string) string {
+	if len(call.Named) == 0 {
+		return message
+	}
+	args := make([]string, len(call.Named))
+	for i, arg := range call.Named {
+		code, _ := c.expression(arg.Value)
+		args[i] = strconv.Quote(arg.Name) + ": " + code
+	}
+	return "t.i18n.Format(" + message + ", map[string]interface{}{" + strings.Join(args, ", ") + "})"
+}
+
+var numericTypes = map[string]bool{
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true, "uintptr": true,
+	"byte": true, "rune": true,
+	"float32": true, "float64": true,
+}
+
+func isNumeric(goType string) bool {
+	return numericTypes[goType]
+}
+
+func isFloat(goType string) bool {
+	return goType == "float32" || goType == "float64"
+}
+
+// isBuiltin reports whether the type is a predeclared Go type.
+func isBuiltin(goType string) bool {
+	return isNumeric(goType) || goType == "string" || goType == "bool" || goType == "error"
+}
+
+// isNillable reports whether nil is the zero value of the type.
+func isNillable(goType string) bool {
+	return goType == "error" || strings.HasPrefix(goType, "*") || strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
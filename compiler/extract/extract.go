@@ -0,0 +1,350 @@
+// Package extract collects translatable strings of templates and Go sources into gettext catalogs.
+//
+// In templates it finds calls of the translation functions _, _n, _p and _np with literal strings,
+// in Go sources calls of GetText, NGetText, PGetText and NPGetText methods and of
+// templates.PGetText and templates.NPGetText. Generated Go files are skipped:
+// their strings come from templates.
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/compiler/codegen"
+	"github.com/strongo/templates/parse"
+)
+
+// DefaultPluralForms is the Plural-Forms header of catalogs lacking one, the rule of English.
+const DefaultPluralForms = "nplurals=2; plural=(n != 1);"
+
+// Message is a translatable string found in sources.
+type Message struct {
+	Context    string
+	ID         string   // the source string, the singular one of plural messages
+	Plural     string   // the plural source string; empty if the message has no plural forms
+	References []string // locations of the message, e.g. "index.html:12"
+}
+
+// Extractor collects messages of templates and Go files in order of their first occurrence.
+type Extractor struct {
+	messages []*Message
+	index    map[string]*Message // by catalog key, see templates.ContextKey
+}
+
+func NewExtractor() *Extractor {
+	return &Extractor{index: make(map[string]*Message)}
+}
+
+// Messages returns the collected messages.
+func (e *Extractor) Messages() []*Message {
+	return e.messages
+}
+
+func (e *Extractor) add(context, id, plural, reference string) {
+	key := templates.ContextKey(context, id)
+	m := e.index[key]
+	if m == nil {
+		m = &Message{Context: context, ID: id}
+		e.index[key] = m
+		e.messages = append(e.messages, m)
+	}
+	if m.Plural == "" {
+		m.Plural = plural
+	}
+	m.References = append(m.References, reference)
+}
+
+// translationFuncs maps translation functions of templates to the indexes of their context,
+// source string and plural source string arguments; -1 if the function has no such argument.
+var translationFuncs = map[string][3]int{
+	"_":   {-1, 0, -1},
+	"_n":  {-1, 0, 1},
+	"_p":  {0, 1, -1},
+	"_np": {0, 1, 2},
+}
+
+// AddTemplate collects messages of the template; name is its path relative to the templates root.
+// Calls with arguments other than string literals are skipped.
+func (e *Extractor) AddTemplate(name, source string) error {
+	tree, err := parse.New(name).Parse(source, "", "", "", make(map[string]*parse.Tree))
+	if err != nil {
+		return err
+	}
+	parse.Inspect(tree.Root, func(node parse.Node) bool {
+		call, ok := node.(*parse.CallNode)
+		if !ok {
+			return true
+		}
+		args, ok := translationFuncs[call.Func.Ident]
+		if !ok {
+			return true
+		}
+		var strs [3]string
+		for i, arg := range args {
+			if arg < 0 {
+				continue
+			}
+			if arg >= len(call.Args) {
+				return true
+			}
+			s, ok := call.Args[arg].(*parse.StringNode)
+			if !ok {
+				return true
+			}
+			strs[i] = s.Text
+		}
+		e.add(strs[0], strs[1], strs[2], fmt.Sprintf("%s:%d", name, tree.LineNumber(call)))
+		return true
+	})
+	return nil
+}
+
+// goTranslationFuncs maps translation methods of templates.I18n to the indexes of their context,
+// source string and plural source string arguments, as translationFuncs does for templates.
+// templates.PGetText and templates.NPGetText take the I18n as an extra first argument.
+var goTranslationFuncs = map[string][3]int{
+	"GetText":   {-1, 0, -1},
+	"NGetText":  {-1, 0, 1},
+	"PGetText":  {0, 1, -1},
+	"NPGetText": {0, 1, 2},
+}
+
+var generated = regexp.MustCompile(`(?m)^// Code generated .* DO NOT EDIT\.$`)
+
+// AddGoSource collects messages of the Go file unless it is generated.
+func (e *Extractor) AddGoSource(name string, source []byte) error {
+	if generated.Match(source) {
+		return nil
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, source, 0)
+	if err != nil {
+		return err
+	}
+	ast.Inspect(f, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		args, ok := goTranslationFuncs[selector.Sel.Name]
+		if !ok {
+			return true
+		}
+		callArgs := call.Args
+		if pkg, ok := selector.X.(*ast.Ident); ok && pkg.Name == "templates" {
+			if len(callArgs) == 0 {
+				return true
+			}
+			callArgs = callArgs[1:] // the I18n
+		}
+		var strs [3]string
+		for i, arg := range args {
+			if arg < 0 {
+				continue
+			}
+			if arg >= len(callArgs) {
+				return true
+			}
+			lit, ok := callArgs[arg].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			if strs[i], err = strconv.Unquote(lit.Value); err != nil {
+				return true
+			}
+		}
+		e.add(strs[0], strs[1], strs[2], fmt.Sprintf("%s:%d", name, fset.Position(call.Pos()).Line))
+		return true
+	})
+	return nil
+}
+
+// AddDir collects messages of templates and Go files found in the directory and its subdirectories.
+// References are relative to the directory.
+func (e *Extractor) AddDir(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if info.IsDir() || ext != codegen.TemplateExt && ext != ".go" || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if ext == ".go" {
+			return e.AddGoSource(filepath.ToSlash(name), source)
+		}
+		return e.AddTemplate(filepath.ToSlash(name), string(source))
+	})
+}
+
+// header returns the header entry of a catalog with the fields, e.g. "Language: ru_RU".
+func header(fields ...string) *templates.POEntry {
+	fields = append([]string{"Content-Type: text/plain; charset=UTF-8", "Content-Transfer-Encoding: 8bit"}, fields...)
+	return &templates.POEntry{Translations: []string{strings.Join(fields, "\n") + "\n"}}
+}
+
+// Template returns the .pot file of the collected messages.
+func (e *Extractor) Template() *templates.POFile {
+	h := header("Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;")
+	h.Flags = []string{"fuzzy"}
+	pot := &templates.POFile{Entries: []*templates.POEntry{h}}
+	for _, m := range e.messages {
+		entry := &templates.POEntry{
+			References:   m.References,
+			Context:      m.Context,
+			ID:           m.ID,
+			Plural:       m.Plural,
+			Translations: []string{""},
+		}
+		if m.Plural != "" {
+			entry.Translations = []string{"", ""}
+		}
+		pot.Entries = append(pot.Entries, entry)
+	}
+	return pot
+}
+
+// NewCatalog returns an empty catalog of the locale with its plural rule, see templates.PluralFormsHeader.
+func NewCatalog(locale string) *templates.POFile {
+	return &templates.POFile{Entries: []*templates.POEntry{
+		header("Language: "+locale, "Plural-Forms: "+templates.PluralFormsHeader(locale)),
+	}}
+}
+
+// Merge updates the catalog of a locale with messages of the template, as msgmerge does:
+// translations of messages still in the template are kept and their references updated,
+// new messages are added untranslated and messages missing from the template are marked obsolete.
+// Messages follow the order of the template, obsolete ones go last.
+func Merge(catalog, pot *templates.POFile) (*templates.POFile, error) {
+	pluralForms := catalog.HeaderField("Plural-Forms")
+	if pluralForms == "" {
+		pluralForms = DefaultPluralForms
+	}
+	nplurals, _, err := templates.ParsePluralForms(pluralForms)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*templates.POEntry)
+	for _, entry := range catalog.Entries {
+		if entry.ID != "" || entry.Context != "" {
+			existing[entry.Key()] = entry
+		}
+	}
+	merged := new(templates.POFile)
+	if h := catalog.Header(); h != nil {
+		merged.Entries = append(merged.Entries, h)
+	} else {
+		merged.Entries = append(merged.Entries, header("Plural-Forms: "+pluralForms))
+	}
+	seen := make(map[string]bool)
+	for _, m := range pot.Entries {
+		if m.ID == "" && m.Context == "" {
+			continue
+		}
+		key := m.Key()
+		seen[key] = true
+		entry := existing[key]
+		if entry == nil {
+			entry = &templates.POEntry{Context: m.Context, ID: m.ID}
+		}
+		entry.References, entry.Plural, entry.Obsolete = m.References, m.Plural, false
+		entry.ExtractedComments = m.ExtractedComments
+		forms := 1
+		if m.Plural != "" {
+			forms = nplurals
+		}
+		for len(entry.Translations) < forms {
+			entry.Translations = append(entry.Translations, "")
+		}
+		entry.Translations = entry.Translations[:forms]
+		merged.Entries = append(merged.Entries, entry)
+	}
+	for _, entry := range catalog.Entries {
+		if (entry.ID != "" || entry.Context != "") && !seen[entry.Key()] {
+			entry.Obsolete = true
+			entry.References = nil
+			merged.Entries = append(merged.Entries, entry)
+		}
+	}
+	return merged, nil
+}
+
+// Update writes the .pot file of messages in sourceDir and merges them into the <locale>.po catalogs
+// of localesDir, creating catalogs of newLocales missing there; see Merge.
+// Either file argument may be empty to skip it.
+func Update(sourceDir, potFile, localesDir string, newLocales []string) error {
+	e := NewExtractor()
+	if err := e.AddDir(sourceDir); err != nil {
+		return err
+	}
+	pot := e.Template()
+	if potFile != "" {
+		if err := writePO(potFile, pot); err != nil {
+			return err
+		}
+	}
+	if localesDir == "" {
+		return nil
+	}
+	paths, err := filepath.Glob(filepath.Join(localesDir, "*.po"))
+	if err != nil {
+		return err
+	}
+	for _, locale := range newLocales {
+		path := filepath.Join(localesDir, locale+".po")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err = writePO(path, NewCatalog(locale)); err != nil {
+				return err
+			}
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		catalog, err := templates.ParsePO(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if catalog, err = Merge(catalog, pot); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if err = writePO(path, catalog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writePO(path string, po *templates.POFile) error {
+	var b bytes.Buffer
+	if err := po.Write(&b); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,209 @@
+package extract
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strongo/templates"
+)
+
+const indexHTML = `@params(count int, key string)
+{{ block title }}{{ _("Welcome to page") }}{{ endblock }}
+{{ block content }}
+{{ _n("%d message", "%d messages", count) }}
+{{ _p("menu", "Open") }}
+{{ _np("inbox", "%d message", "%d messages", count, name=_("you")) }}
+{{ _(key) }}{{ _("Welcome to page") }}
+{{ endblock }}`
+
+const componentGo = `package code
+
+import "github.com/strongo/templates"
+
+func render(i18n templates.I18n, key string) string {
+	return i18n.GetText("Put your content here.") + i18n.NGetText("%d author", "%d authors", 2) +
+		templates.PGetText(i18n, "menu", "Open") + i18n.GetText(key)
+}
+`
+
+func TestExtractor(t *testing.T) {
+	e := NewExtractor()
+	if err := e.AddTemplate("index.html", indexHTML); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddGoSource("component.go", []byte(componentGo)); err != nil {
+		t.Fatal(err)
+	}
+	generated := "// Code generated by strongo from index.html. DO NOT EDIT.\n\n" + componentGo
+	if err := e.AddGoSource("index_html.go", []byte(generated)); err != nil {
+		t.Fatal(err)
+	}
+	var result []string
+	for _, m := range e.Messages() {
+		result = append(result, templates.ContextKey(m.Context, m.ID)+"|"+m.Plural+"|"+strings.Join(m.References, " "))
+	}
+	expected := []string{
+		"Welcome to page||index.html:2 index.html:7",
+		"%d message|%d messages|index.html:4",
+		"menu\x04Open||index.html:5 component.go:7",
+		"inbox\x04%d message|%d messages|index.html:6",
+		"you||index.html:6",
+		"Put your content here.||component.go:6",
+		"%d author|%d authors|component.go:6",
+	}
+	if strings.Join(result, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected messages\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(result, "\n"))
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	e := NewExtractor()
+	if err := e.AddTemplate("index.html", `{{ _("Hi") }}{{ _n("%d message", "%d messages", 1) }}`); err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err := e.Template().Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `#, fuzzy
+msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=INTEGER; plural=EXPRESSION;\n"
+
+#: index.html:1
+msgid "Hi"
+msgstr ""
+
+#: index.html:1
+msgid "%d message"
+msgid_plural "%d messages"
+msgstr[0] ""
+msgstr[1] ""
+`
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
+
+const ruPO = `msgid ""
+msgstr ""
+"Language: ru_RU\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+# checked by Anna
+#: layout.html:12
+msgid "Welcome to page"
+msgstr "Добро пожаловать на страницу"
+
+#: index.html:1
+msgid "Removed"
+msgstr "Удалено"
+
+#~ msgid "Open"
+#~ msgstr "Открыть"
+`
+
+func TestMerge(t *testing.T) {
+	catalog, err := templates.ParsePO(strings.NewReader(ruPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := NewExtractor()
+	if err = e.AddTemplate("index.html", "{{ _(\"Welcome to page\") }}\n{{ _(\"Open\") }}{{ _n(\"%d message\", \"%d messages\", 1) }}"); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := Merge(catalog, e.Template())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b bytes.Buffer
+	if err = merged.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	expected := `msgid ""
+msgstr ""
+"Language: ru_RU\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+# checked by Anna
+#: index.html:1
+msgid "Welcome to page"
+msgstr "Добро пожаловать на страницу"
+
+#: index.html:2
+msgid "Open"
+msgstr "Открыть"
+
+#: index.html:2
+msgid "%d message"
+msgid_plural "%d messages"
+msgstr[0] ""
+msgstr[1] ""
+msgstr[2] ""
+
+#~ msgid "Removed"
+#~ msgstr "Удалено"
+`
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extract")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, locales := filepath.Join(dir, "templates"), filepath.Join(dir, "locales")
+	for _, d := range []string{filepath.Join(src, "folder"), locales} {
+		if err = os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range map[string]string{
+		filepath.Join(src, "folder", "index.html"): indexHTML,
+		filepath.Join(src, "component.go"):         componentGo,
+		filepath.Join(locales, "ru_RU.po"):         ruPO,
+	} {
+		if err = ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pot := filepath.Join(dir, "messages.pot")
+	if err = Update(src, pot, locales, []string{"pl", "ru_RU"}); err != nil {
+		t.Fatal(err)
+	}
+	potFile, err := ioutil.ReadFile(pot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(potFile), "#: folder/index.html:2 folder/index.html:7\nmsgid \"Welcome to page\"") {
+		t.Errorf("unexpected .pot file:\n%s", potFile)
+	}
+	catalogs, err := templates.LoadCatalogs(locales, "en", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if locales := catalogs.Locales(); strings.Join(locales, " ") != "en pl ru_RU" {
+		t.Errorf("unexpected locales: %v", locales)
+	}
+	if text := catalogs.I18n("ru_RU").GetText("Welcome to page"); text != "Добро пожаловать на страницу" {
+		t.Errorf("the translation should be kept, got %q", text)
+	}
+	pl, err := ioutil.ReadFile(filepath.Join(locales, "pl.po"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"Language: pl\n"`, "nplurals=3;", "msgctxt \"inbox\"\nmsgid \"%d message\"\nmsgid_plural \"%d messages\"\nmsgstr[0] \"\"\nmsgstr[1] \"\"\nmsgstr[2] \"\"\n"} {
+		if !strings.Contains(string(pl), s) {
+			t.Errorf("pl.po should contain %q:\n%s", s, pl)
+		}
+	}
+}
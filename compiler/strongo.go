@@ -6,17 +6,46 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"./strongorazor/gorazor"
+	"github.com/strongo/templates/compiler/extract"
 )
 
 func Usage() {
 	fmt.Fprintf(os.Stderr, "usage: strongo [-debug] [-watch] <input dir or file> <output dir or file>\n")
+	fmt.Fprintf(os.Stderr, "       strongo i18n extract [-pot file] [-locales dir] [-new locales] <templates dir>\n")
 	flag.PrintDefaults()
 	os.Exit(1)
 }
 
+// i18nExtract runs "strongo i18n extract": it writes translatable strings of templates and Go files
+// to a .pot file and merges them into the .po catalogs of the locales dir.
+func i18nExtract(args []string) {
+	flags := flag.NewFlagSet("i18n extract", flag.ExitOnError)
+	pot := flags.String("pot", "messages.pot", "translation template to write; none if empty")
+	locales := flags.String("locales", "", "dir of <locale>.po catalogs to merge new strings into")
+	newLocales := flags.String("new", "", "comma separated locales to create catalogs for, e.g. ru_RU,de")
+	flags.Usage = Usage
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		Usage()
+	}
+	var create []string
+	if *newLocales != "" {
+		create = strings.Split(*newLocales, ",")
+	}
+	if err := extract.Update(flags.Arg(0), *pot, *locales, create); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
 func main() {
+	if len(os.Args) > 2 && os.Args[1] == "i18n" && os.Args[2] == "extract" {
+		i18nExtract(os.Args[3:])
+		return
+	}
 	flag.Usage = Usage
 	isDebug := flag.Bool("debug", false, "use debug mode")
 	isWatch := flag.Bool("watch", false, "use watch mode")
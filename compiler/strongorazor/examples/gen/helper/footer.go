@@ -2,11 +2,12 @@ package helper
 
 import (
 	"bytes"
+	"github.com/strongo/templates"
 )
 
-func Footer() string {
+func Footer() templates.HTML {
 	var _buffer bytes.Buffer
 	_buffer.WriteString("<div>copyright 2014</div>")
 
-	return _buffer.String()
+	return templates.HTML(_buffer.String())
 }
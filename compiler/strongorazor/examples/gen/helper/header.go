@@ -2,11 +2,12 @@ package helper
 
 import (
 	"bytes"
+	"github.com/strongo/templates"
 )
 
-func Header() string {
+func Header() templates.HTML {
 	var _buffer bytes.Buffer
 	_buffer.WriteString("<div>Page Header</div>")
 
-	return _buffer.String()
+	return templates.HTML(_buffer.String())
 }
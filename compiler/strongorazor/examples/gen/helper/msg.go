@@ -2,11 +2,11 @@ package helper
 
 import (
 	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"github.com/strongo/templates"
 	. "kp/models"
 )
 
-func Msg(u *User) string {
+func Msg(u *User) templates.HTML {
 	var _buffer bytes.Buffer
 
 	username := u.Name
@@ -15,10 +15,10 @@ func Msg(u *User) string {
 	}
 
 	_buffer.WriteString("\n<div class=\"welcome\">\n<h4>Hello ")
-	_buffer.WriteString(gorazor.HTMLEscape(username))
+	_buffer.WriteString(templates.EscapeHTML(username))
 	_buffer.WriteString("</h4>\n\n<div>")
-	_buffer.WriteString((u.Intro))
+	_buffer.WriteString(templates.EscapeHTML(templates.HTML(u.Intro)))
 	_buffer.WriteString("</div>\n</div>")
 
-	return _buffer.String()
+	return templates.HTML(_buffer.String())
 }
@@ -2,38 +2,102 @@ package tpl
 
 import (
 	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	. "kp/models"
 	"tpl/helper"
 	"tpl/layout"
 )
 
-func Home(totalMessage int, u *User) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString((helper.Header()))
-	_buffer.WriteString((helper.Msg(u)))
+const source_Home = "@{\n\timport (\n\t\t. \"kp/models\"\n\t\t\"tpl/helper\"\n\t\t\"tpl/layout/base\"\n\t)\n\tvar totalMessage int\n\tvar u *User\n}\n\n\n@helper.Header()\n@helper.Msg(u)\n\n@for i := 0; i < 2; i++ {\n\t@if totalMessage > 0 {\n\t\t@if totalMessage == 1 {\n\t\t\t<p>@u.Name has 1 message</p>\n\t\t} else {\n\t\t\t<p>@u.Name has @gorazor.Itoa(totalMessage) messages</p>\n\t\t}\n\t} else {\n\t\t<p>@u.Name has no messages</p>\n\t}\n}\n\n\n@{\n\tfor i := 0; i < 2; i++ {\n\t\tif totalMessage > 0 {\n\t\t\tif totalMessage == 1 {\n\t\t\t\t<p>@u.Name has 1 message</p>\n\t\t\t} else {\n\t\t\t\t<p>@u.Name has @gorazor.Itoa(totalMessage) messages</p>\n\t\t\t}\n\t\t} else {\n\t\t\t<p>@u.Name has no messages</p>\n\t\t}\n\t}\n}\n\n@{\n\tswitch totalMessage {\n\tcase 1:\n\t      <p>@u.Name has 1  message</p>\n\tcase 2:\n\t      <p>@u.Name has 2 messages</p>\n\tdefault:\n\t      <p>@u.Name has no messages</p>\n\t}\n}\n\n@helper.Footer()\n\n@section title {\n\t<title>@u.Name's homepage</title>\n}\n\n@section side {\n\t\n}"
+
+// Home renders tpl/home.gohtml.
+type Home struct {
+	totalMessage int
+	u            *User
+}
+
+func New_Home(totalMessage int, u *User) templates.Template {
+	return &Home{
+		totalMessage: totalMessage,
+		u:            u,
+	}
+}
+
+func (t *Home) Name() string {
+	return "home.gohtml"
+}
+
+func (t *Home) Path() string {
+	return "tpl/home.gohtml"
+}
+
+func (t *Home) Source() string {
+	return source_Home
+}
+
+func (t *Home) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Home) Render(c templates.RenderContext) error {
+	return render_Home(c, t.totalMessage, t.u)
+}
+
+func render_Home(c templates.RenderContext, totalMessage int, u *User) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
+	if _, err := c.WriteString(templates.EscapeHTML(helper.Header())); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(helper.Msg(u))); err != nil {
+		return err
+	}
 	for i := 0; i < 2; i++ {
 		if totalMessage > 0 {
 			if totalMessage == 1 {
 
-				_buffer.WriteString("<p>")
-				_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-				_buffer.WriteString(" has 1 message</p>")
+				if _, err := c.WriteString("<p>"); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" has 1 message</p>"); err != nil {
+					return err
+				}
 
 			} else {
 
-				_buffer.WriteString("<p>")
-				_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-				_buffer.WriteString(" has ")
-				_buffer.WriteString(gorazor.HTMLEscape(gorazor.Itoa(totalMessage)))
-				_buffer.WriteString(" messages</p>")
+				if _, err := c.WriteString("<p>"); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" has "); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(gorazor.Itoa(totalMessage))); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" messages</p>"); err != nil {
+					return err
+				}
 
 			}
 		} else {
 
-			_buffer.WriteString("<p>")
-			_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-			_buffer.WriteString(" has no messages</p>")
+			if _, err := c.WriteString("<p>"); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(" has no messages</p>"); err != nil {
+				return err
+			}
 
 		}
 	}
@@ -42,24 +106,46 @@ func Home(totalMessage int, u *User) string {
 		if totalMessage > 0 {
 			if totalMessage == 1 {
 
-				_buffer.WriteString("<p>")
-				_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-				_buffer.WriteString(" has 1 message</p>")
+				if _, err := c.WriteString("<p>"); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" has 1 message</p>"); err != nil {
+					return err
+				}
 
 			} else {
 
-				_buffer.WriteString("<p>")
-				_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-				_buffer.WriteString(" has ")
-				_buffer.WriteString(gorazor.HTMLEscape(gorazor.Itoa(totalMessage)))
-				_buffer.WriteString(" messages</p>")
+				if _, err := c.WriteString("<p>"); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" has "); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(gorazor.Itoa(totalMessage))); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(" messages</p>"); err != nil {
+					return err
+				}
 
 			}
 		} else {
 
-			_buffer.WriteString("<p>")
-			_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-			_buffer.WriteString(" has no messages</p>")
+			if _, err := c.WriteString("<p>"); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(" has no messages</p>"); err != nil {
+				return err
+			}
 
 		}
 	}
@@ -67,41 +153,74 @@ func Home(totalMessage int, u *User) string {
 	switch totalMessage {
 	case 1:
 
-		_buffer.WriteString("<p>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-		_buffer.WriteString(" has 1  message</p>")
+		if _, err := c.WriteString("<p>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(" has 1  message</p>"); err != nil {
+			return err
+		}
 
 	case 2:
 
-		_buffer.WriteString("<p>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-		_buffer.WriteString(" has 2 messages</p>")
+		if _, err := c.WriteString("<p>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(" has 2 messages</p>"); err != nil {
+			return err
+		}
 
 	default:
 
-		_buffer.WriteString("<p>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-		_buffer.WriteString(" has no messages</p>")
+		if _, err := c.WriteString("<p>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(" has no messages</p>"); err != nil {
+			return err
+		}
 
 	}
 
-	_buffer.WriteString((helper.Footer()))
-
-	title := func() string {
-		var _buffer bytes.Buffer
+	if _, err := c.WriteString(templates.EscapeHTML(helper.Footer())); err != nil {
+		return err
+	}
+	title := func(c templates.RenderContext) error {
 
-		_buffer.WriteString("<title>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-		_buffer.WriteString("'s homepage</title>")
+		if _, err := c.WriteString("<title>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("'s homepage</title>"); err != nil {
+			return err
+		}
 
-		return _buffer.String()
+		return nil
 	}
+	side := func(c templates.RenderContext) error {
 
-	side := func() string {
-		var _buffer bytes.Buffer
-
-		return _buffer.String()
+		return nil
 	}
 
-	return layout.Base(_buffer.String(), title(), side())
+	var _title bytes.Buffer
+	c.Writer = &_title
+	if err := title(c); err != nil {
+		return err
+	}
+	var _side bytes.Buffer
+	c.Writer = &_side
+	if err := side(c); err != nil {
+		return err
+	}
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), templates.HTML(_title.String()), templates.HTML(_side.String())).Render(c)
 }
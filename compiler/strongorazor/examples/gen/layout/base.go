@@ -1,18 +1,69 @@
 package layout
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Base(body string, title string, side string) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />")
-	_buffer.WriteString((title))
-	_buffer.WriteString("\n</head>\n<body>\n<div>")
-	_buffer.WriteString((body))
-	_buffer.WriteString("</div>\n<div>")
-	_buffer.WriteString((side))
-	_buffer.WriteString("</div>\n</body>\n</html>")
-
-	return _buffer.String()
+const source_Base = "@{\n\tvar body templates.HTML\n\tvar title string\n\tvar side templates.HTML\n}\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />\n@title\n</head>\n<body>\n<div>@body</div>\n<div>@side</div>\n</body>\n</html>"
+
+// Base renders layout/base.gohtml.
+type Base struct {
+	body  templates.HTML
+	title string
+	side  templates.HTML
+}
+
+func New_Base(body templates.HTML, title string, side templates.HTML) templates.Template {
+	return &Base{
+		body:  body,
+		title: title,
+		side:  side,
+	}
+}
+
+func (t *Base) Name() string {
+	return "base.gohtml"
+}
+
+func (t *Base) Path() string {
+	return "layout/base.gohtml"
+}
+
+func (t *Base) Source() string {
+	return source_Base
+}
+
+func (t *Base) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Base) Render(c templates.RenderContext) error {
+	return render_Base(c, t.body, t.title, t.side)
+}
+
+func render_Base(c templates.RenderContext, body templates.HTML, title string, side templates.HTML) error {
+	if _, err := c.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(title)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n</head>\n<body>\n<div>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(body)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</div>\n<div>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(side)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</div>\n</body>\n</html>"); err != nil {
+		return err
+	}
+
+	return nil
 }
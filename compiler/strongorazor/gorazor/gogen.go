@@ -9,6 +9,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	"gopkg.in/fsnotify.v1"
@@ -53,6 +55,8 @@ type Compiler struct {
 	options  Option
 	dir      string
 	file     string
+	name     string // file name of the template
+	source   string // source code of the template
 }
 
 func (self *Compiler) addPart(part Part) {
@@ -78,8 +82,8 @@ func (self *Compiler) genPart() {
 				p.value = p.value[:len(p.value)-1]
 			}
 			if p.value != "" {
-				p.value = fmt.Sprintf("%#v", p.value)
-				res += "\tif _, err := c.WriteString(" + p.value + "); err != nil {\n\treturn err\n}\n"
+				start, end := self.writeCall()
+				res += start + fmt.Sprintf("%#v", p.value) + end
 			}
 		} else if p.ptype == CBLK {
 			res += p.value + "\n"
@@ -90,6 +94,15 @@ func (self *Compiler) genPart() {
 	self.buf = res
 }
 
+// writeCall returns the code starting and ending a call writing a string:
+// helpers write to their buffer, templates write to the render context and return its error.
+func (cp *Compiler) writeCall() (start, end string) {
+	if cp.dir == "helper" {
+		return "_buffer.WriteString(", ")\n"
+	}
+	return "if _, err := c.WriteString(", "); err != nil {\n\treturn err\n}\n"
+}
+
 func makeCompiler(ast *Ast, options Option, input string) *Compiler {
 	dir := filepath.Base(filepath.Dir(input))
 	file := strings.Replace(filepath.Base(input), gz_extension, "", 1)
@@ -103,6 +116,7 @@ func makeCompiler(ast *Ast, options Option, input string) *Compiler {
 		options: options,
 		dir:     dir,
 		file:    file,
+		name:    filepath.Base(input),
 	}
 }
 
@@ -193,7 +207,6 @@ func (cp *Compiler) visitExp(child interface{}, parent *Ast, idx int, isHomo boo
 	end := ""
 	ppNotExp := true
 	ppChildCnt := len(parent.Children)
-	pack := cp.dir
 	htmlEsc := cp.options["htmlEscape"]
 	if parent.Parent != nil && parent.Parent.Mode == EXP {
 		ppNotExp = false
@@ -201,45 +214,24 @@ func (cp *Compiler) visitExp(child interface{}, parent *Ast, idx int, isHomo boo
 	val := getValStr(child)
 	if htmlEsc == nil {
 		if ppNotExp && idx == 0 && isHomo {
-			needEsape := true
-			switch {
-			case val == "helper" || val == "html" || val == "raw":
-				needEsape = false
-			case pack == "layout":
-				needEsape = true
-				for _, param := range cp.params {
-					if strings.HasPrefix(param, val+" ") {
-						needEsape = false
-						break
-					}
-				}
-			}
-
-			if needEsape {
-				start += "gorazor.HTMLEscape("
-				cp.imports[StrongoNamespace] = true
-			} else {
-				start += "("
-			}
+			// templates.EscapeHTML writes values of safe content types as they are, e.g. templates.HTML returned by helpers.
+			start += "templates.EscapeHTML("
+			cp.imports[StrongoNamespace] = true
 		}
 		if ppNotExp && idx == ppChildCnt-1 && isHomo {
 			end += ")"
 		}
 	}
 
+	writeStart, writeEnd := cp.writeCall()
 	if ppNotExp && idx == 0 {
-		start = "if _, err := c.WriteString(" + start
+		start = writeStart + start
 	}
 	if ppNotExp && idx == ppChildCnt-1 {
-		end += "); err != nil {\n\treturn err\n}\n"
+		end += writeEnd
 	}
 
-	v := start
-	if val == "raw" {
-		v += end
-	} else {
-		v += val + end
-	}
+	v := start + val + end
 	cp.addPart(Part{CSTAT, v})
 }
 
@@ -297,6 +289,8 @@ func (cp *Compiler) visitAst(ast *Ast) {
 	}
 }
 
+// processLayout turns sections into closures rendering them and writes the end of the render function:
+// templates with a layout render their body and sections into buffers and pass them to the layout as templates.HTML.
 // TODO, this is dirty now
 func (cp *Compiler) processLayout() {
 	lines := strings.SplitN(cp.buf, "\n", -1)
@@ -308,18 +302,19 @@ func (cp *Compiler) processLayout() {
 		if strings.HasPrefix(l, "section") && strings.HasSuffix(l, "{") {
 			name := l
 			name = strings.TrimSpace(name[7 : len(name)-1])
-			out += "\nfunc(t " + "TemplateType" + ") RenderBlock_" + name + "(c RenderContext) error {"
+			out += name + " := func(c templates.RenderContext) error {\n"
 			scope = 1
 			sections = append(sections, name)
 		} else if scope > 0 {
-			if strings.HasSuffix(l, "{") {
+			if strings.HasPrefix(l, "}") && strings.HasSuffix(l, "{") {
+				// e.g. } else {
+			} else if strings.HasSuffix(l, "{") {
 				scope++
 			} else if strings.HasSuffix(l, "}") {
 				scope--
 			}
 			if scope == 0 {
-				out += "}\n" //"return _buffer.String()\n}\n"
-				scope = 0
+				out += "return nil\n}\n"
 			} else {
 				out += l + "\n"
 			}
@@ -328,17 +323,28 @@ func (cp *Compiler) processLayout() {
 		}
 	}
 	cp.buf = out
-	foot := "\nreturn "
-	if cp.layout != "" {
-		parts := strings.SplitN(cp.layout, "/", -1)
-		base := Capitalize(parts[len(parts)-1])
-		foot += "layout." + base + "("
+	if cp.dir == "helper" {
+		cp.buf += "return templates.HTML(_buffer.String())\n}\n"
+		return
 	}
-	//foot += "_buffer.String()"
+	if cp.layout == "" {
+		cp.buf += "return nil\n}\n"
+		return
+	}
+	foot := ""
+	for _, sec := range sections {
+		foot += "var _" + sec + " bytes.Buffer\n"
+		foot += "c.Writer = &_" + sec + "\n"
+		foot += "if err := " + sec + "(c); err != nil {\nreturn err\n}\n"
+	}
+	parts := strings.SplitN(cp.layout, "/", -1)
+	base := Capitalize(parts[len(parts)-1])
+	foot += "c.Writer = _writer\n"
+	foot += "return layout.New_" + base + "(templates.HTML(_body.String())"
 	args := LayOutArgs(cp.layout)
 	if len(args) == 0 {
 		for _, sec := range sections {
-			foot += ", " + sec + "()"
+			foot += ", templates.HTML(_" + sec + ".String())"
 		}
 	} else {
 		for idx, arg := range args {
@@ -346,13 +352,12 @@ func (cp *Compiler) processLayout() {
 			if idx == 0 {
 				continue
 			}
-			arg = strings.Replace(arg, "string", "", -1)
-			arg = strings.TrimSpace(arg)
+			arg = strings.Fields(arg)[0] // the name of a section param, e.g. "body templates.HTML"
 			found := false
 			for _, sec := range sections {
 				if sec == arg {
 					found = true
-					foot += ", " + sec + "()"
+					foot += ", templates.HTML(_" + sec + ".String())"
 					break
 				}
 			}
@@ -361,13 +366,19 @@ func (cp *Compiler) processLayout() {
 			}
 		}
 	}
-	if cp.layout != "" {
-		foot += ")"
-	}
-	foot += "\n}\n"
+	foot += ").Render(c)\n}\n"
 	cp.buf += foot
 }
 
+// splitParam returns the name and the type of a param declared by var in the first block, e.g. u *User.
+func splitParam(param string) (name, typ string) {
+	fields := strings.SplitN(strings.TrimSpace(param), " ", 2)
+	if len(fields) < 2 {
+		return fields[0], ""
+	}
+	return fields[0], strings.TrimSpace(fields[1])
+}
+
 func (cp *Compiler) visit() {
 	cp.ast.debug(0, 1000)
 	cp.visitAst(cp.ast)
@@ -376,22 +387,67 @@ func (cp *Compiler) visit() {
 	pack := cp.dir
 	fun := cp.file
 
-	//cp.imports[`"bytes"`] = true
+	cp.imports[StrongoNamespace] = true
+	if pack == "helper" || cp.layout != "" {
+		cp.imports[`"bytes"`] = true
+	}
+	if pack != "helper" {
+		cp.imports[`"context"`] = true
+	}
+	imports := make([]string, 0, len(cp.imports))
+	for k := range cp.imports {
+		imports = append(imports, k)
+	}
+	sort.Strings(imports) // for the output to not change between runs
 	head := "package " + pack + "\n import (\n"
-	for k, _ := range cp.imports {
+	for _, k := range imports {
 		head += "\t" + k + "\n"
 	}
 	head += "\n)\n\n"
-	head += "type " + fun + " struct {\n\tpayload map[string]string\n}\n\n"
+	params := strings.Join(cp.params, ", ")
+	if pack == "helper" {
+		// Helpers render into a buffer and return templates.HTML, so templates calling them do not escape their output.
+		head += "func " + fun + "(" + params + ") templates.HTML {\n"
+		head += "var _buffer bytes.Buffer\n"
+		cp.buf = head + cp.buf
+		cp.processLayout()
+		return
+	}
 
-	head += "func New_" + fun + "("
-	for idx, p := range cp.params {
-		head += p
-		if idx != len(cp.params)-1 {
-			head += ", "
+	fields, values, args := "", "", ""
+	for _, p := range cp.params {
+		name, typ := splitParam(p)
+		arg := "t." + name
+		if strings.HasPrefix(typ, "...") {
+			typ, arg = "[]"+typ[3:], arg+"..."
 		}
+		fields += name + " " + typ + "\n"
+		values += name + ": " + name + ",\n"
+		args += ", " + arg
+	}
+	path := pack + "/" + cp.name
+	head += "const source_" + fun + " = " + strconv.Quote(cp.source) + "\n\n"
+	head += "// " + fun + " renders " + path + ".\n"
+	head += "type " + fun + " struct {\n" + fields + "}\n\n"
+	head += "func New_" + fun + "(" + params + ") templates.Template {\n"
+	head += "return &" + fun + "{\n" + values + "}\n}\n\n"
+	head += "func (t *" + fun + ") Name() string {\nreturn " + strconv.Quote(cp.name) + "\n}\n\n"
+	head += "func (t *" + fun + ") Path() string {\nreturn " + strconv.Quote(path) + "\n}\n\n"
+	head += "func (t *" + fun + ") Source() string {\nreturn source_" + fun + "\n}\n\n"
+	head += "func (t *" + fun + ") GetData(ctx context.Context) error {\nreturn nil\n}\n\n"
+	head += "func (t *" + fun + ") Render(c templates.RenderContext) error {\n"
+	head += "return render_" + fun + "(c" + args + ")\n}\n\n"
+	// Params are passed as local variables of the render function, as Go code of the template refers to them by name.
+	head += "func render_" + fun + "(c templates.RenderContext"
+	if params != "" {
+		head += ", " + params
+	}
+	head += ") error {\n"
+	if cp.layout != "" {
+		head += "_writer := c.Writer\n"
+		head += "var _body bytes.Buffer\n"
+		head += "c.Writer = &_body\n"
 	}
-	head += ") templates.Template {\n}\n\n"
 	cp.buf = head + cp.buf
 	cp.processLayout()
 }
@@ -435,6 +491,7 @@ func run(path string, Options Option) (*Compiler, error) {
 		}
 	}
 	cp := makeCompiler(parser.ast, Options, path)
+	cp.source = text
 	cp.visit()
 	return cp, nil
 }
@@ -3,36 +3,99 @@ package cases
 import (
 	"bytes"
 	"cases/layout"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Add(content string, err string) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n<link rel=\"stylesheet\" href=\"/css/bootstrap-datetimepicker.css\">\n\n<style>\n.row {\n\tmargin-top: 10px;\n}\n</style>\n\n<h2>日程登记</h2>\n\n<div class=\"container-fluid\">\n\t<form method=\"POST\" action=\"\">\n\t<div class=\"row\" >\n\t\t<p class=\"bg-danger\">")
-	_buffer.WriteString(gorazor.HTMLEscape(err))
-	_buffer.WriteString("</p>\n\t</div>\n\n\t<div class=\"row\">\n\t内容:\n\t<input type='text' class=\"form-control\" name=\"content\" value=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(content))
-	_buffer.WriteString("\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t开始时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"startTime\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t结束时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"endTime\"/>\n\t</div>\n\n\t<div class=\"row\">\n\t日程指派:\n\t<select name=\"appoint\">\n\t\t<option>cheney</option>\n\t\t<option>wuvist</option>\n\t</select>\n\t</div>\n\t\n\t<div class=\"row\">\n\t<input style=\"float:right\" type=\"submit\" value=\"保存\" class=\"btn btn-primary\"/>\n\t</div>\n\t</form>\n</div>")
+const source_Add = "@{\n\timport (\n\t\t\"cases/layout/base\"\n\t)\n\n\tvar content string\n\tvar err string\n}\n\n<link rel=\"stylesheet\" href=\"/css/bootstrap-datetimepicker.css\">\n\n<style>\n.row {\n\tmargin-top: 10px;\n}\n</style>\n\n<h2>日程登记</h2>\n\n<div class=\"container-fluid\">\n\t<form method=\"POST\" action=\"\">\n\t<div class=\"row\" >\n\t\t<p class=\"bg-danger\">@err</p>\n\t</div>\n\n\t<div class=\"row\">\n\t内容:\n\t<input type='text' class=\"form-control\" name=\"content\" value=\"@content\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t开始时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"startTime\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t结束时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"endTime\"/>\n\t</div>\n\n\t<div class=\"row\">\n\t日程指派:\n\t<select name=\"appoint\">\n\t\t<option>cheney</option>\n\t\t<option>wuvist</option>\n\t</select>\n\t</div>\n\t\n\t<div class=\"row\">\n\t<input style=\"float:right\" type=\"submit\" value=\"保存\" class=\"btn btn-primary\"/>\n\t</div>\n\t</form>\n</div>\n\n\n@section title {\n\t@:管理后台 - 添加日程\n}\n\n@section js {\n<script src=\"/js/moment.js\"></script>\n<script src=\"/js/bootstrap-datetimepicker.js\"></script>\n<script type=\"text/javascript\">\n\t$(function () {\n\t\t$(\".datetimepicker\").datetimepicker({\n\t\t\tformat: \"YYYY-MM-DD HH:mm\",\n\t\t\tdefaultDate: \"2014-05-01 00:00\",\n\t\t})\n\t});\n</script>\n}\n"
 
-	title := func() string {
-		var _buffer bytes.Buffer
+// Add renders cases/add.gohtml.
+type Add struct {
+	content string
+	err     string
+}
+
+func New_Add(content string, err string) templates.Template {
+	return &Add{
+		content: content,
+		err:     err,
+	}
+}
+
+func (t *Add) Name() string {
+	return "add.gohtml"
+}
 
-		_buffer.WriteString("管理后台 - 添加日程")
+func (t *Add) Path() string {
+	return "cases/add.gohtml"
+}
+
+func (t *Add) Source() string {
+	return source_Add
+}
+
+func (t *Add) GetData(ctx context.Context) error {
+	return nil
+}
 
-		return _buffer.String()
+func (t *Add) Render(c templates.RenderContext) error {
+	return render_Add(c, t.content, t.err)
+}
+
+func render_Add(c templates.RenderContext, content string, err string) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
+	if _, err := c.WriteString("\n\n<link rel=\"stylesheet\" href=\"/css/bootstrap-datetimepicker.css\">\n\n<style>\n.row {\n\tmargin-top: 10px;\n}\n</style>\n\n<h2>日程登记</h2>\n\n<div class=\"container-fluid\">\n\t<form method=\"POST\" action=\"\">\n\t<div class=\"row\" >\n\t\t<p class=\"bg-danger\">"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(err)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</p>\n\t</div>\n\n\t<div class=\"row\">\n\t内容:\n\t<input type='text' class=\"form-control\" name=\"content\" value=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(content)); err != nil {
+		return err
 	}
+	if _, err := c.WriteString("\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t开始时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"startTime\"/>\n\t</div>\n\t\n\t<div class=\"row\">\n\t结束时间:\n\t<input type='text' class=\"datetimepicker form-control\" name=\"endTime\"/>\n\t</div>\n\n\t<div class=\"row\">\n\t日程指派:\n\t<select name=\"appoint\">\n\t\t<option>cheney</option>\n\t\t<option>wuvist</option>\n\t</select>\n\t</div>\n\t\n\t<div class=\"row\">\n\t<input style=\"float:right\" type=\"submit\" value=\"保存\" class=\"btn btn-primary\"/>\n\t</div>\n\t</form>\n</div>"); err != nil {
+		return err
+	}
+	title := func(c templates.RenderContext) error {
+
+		if _, err := c.WriteString("管理后台 - 添加日程"); err != nil {
+			return err
+		}
 
-	js := func() string {
-		var _buffer bytes.Buffer
+		return nil
+	}
+	js := func(c templates.RenderContext) error {
 
-		_buffer.WriteString("<script src=\"/js/moment.js\"></script>")
+		if _, err := c.WriteString("<script src=\"/js/moment.js\"></script>"); err != nil {
+			return err
+		}
 
-		_buffer.WriteString("<script src=\"/js/bootstrap-datetimepicker.js\"></script>")
+		if _, err := c.WriteString("<script src=\"/js/bootstrap-datetimepicker.js\"></script>"); err != nil {
+			return err
+		}
 
-		_buffer.WriteString("<script type=\"text/javascript\">\n\t$(function () {\n\t\t$(\".datetimepicker\").datetimepicker({\n\t\t\tformat: \"YYYY-MM-DD HH:mm\",\n\t\t\tdefaultDate: \"2014-05-01 00:00\",\n\t\t})\n\t});\n</script>")
+		if _, err := c.WriteString("<script type=\"text/javascript\">\n\t$(function () {\n\t\t$(\".datetimepicker\").datetimepicker({\n\t\t\tformat: \"YYYY-MM-DD HH:mm\",\n\t\t\tdefaultDate: \"2014-05-01 00:00\",\n\t\t})\n\t});\n</script>"); err != nil {
+			return err
+		}
 
-		return _buffer.String()
+		return nil
 	}
 
-	return layout.Base(_buffer.String(), title(), js())
+	var _title bytes.Buffer
+	c.Writer = &_title
+	if err := title(c); err != nil {
+		return err
+	}
+	var _js bytes.Buffer
+	c.Writer = &_js
+	if err := js(c); err != nil {
+		return err
+	}
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), templates.HTML(_title.String()), templates.HTML(_js.String())).Render(c)
 }
@@ -3,19 +3,64 @@ package cases
 import (
 	"bytes"
 	"cases/layout"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	. "kp/models"
 	"tpl/helper"
 )
 
-func Argsbug(totalMessage int, u *User) string {
-	var _buffer bytes.Buffer
+const source_Argsbug = "@{\nimport (\n    . \"kp/models\"\n     \"tpl/helper\"\n     \"cases/layout/args\"\n)\nvar totalMessage int\nvar u *User\n}\n\n@{\n     messages := []string{}\n}\n\n<p>@gorazor.Itoa(args(messages...))</p>\n"
+
+// Argsbug renders cases/argsbug.gohtml.
+type Argsbug struct {
+	totalMessage int
+	u            *User
+}
+
+func New_Argsbug(totalMessage int, u *User) templates.Template {
+	return &Argsbug{
+		totalMessage: totalMessage,
+		u:            u,
+	}
+}
+
+func (t *Argsbug) Name() string {
+	return "argsbug.gohtml"
+}
+
+func (t *Argsbug) Path() string {
+	return "cases/argsbug.gohtml"
+}
+
+func (t *Argsbug) Source() string {
+	return source_Argsbug
+}
+
+func (t *Argsbug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Argsbug) Render(c templates.RenderContext) error {
+	return render_Argsbug(c, t.totalMessage, t.u)
+}
+
+func render_Argsbug(c templates.RenderContext, totalMessage int, u *User) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
 
 	messages := []string{}
 
-	_buffer.WriteString("\n\n<p>")
-	_buffer.WriteString(gorazor.HTMLEscape(gorazor.Itoa(args(messages...))))
-	_buffer.WriteString("</p>")
+	if _, err := c.WriteString("\n\n<p>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(gorazor.Itoa(args(messages...)))); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</p>"); err != nil {
+		return err
+	}
 
-	return layout.Args(_buffer.String())
+	c.Writer = _writer
+	return layout.New_Args(templates.HTML(_body.String())).Render(c)
 }
@@ -1,34 +1,100 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Badtag(w *gorazor.Widget) string {
-	var _buffer bytes.Buffer
+const source_Badtag = "@{\n\tvar w *gorazor.Widget\n}\n\n@if w.ErrorMsg != \"\" {\n\t<div class=\"form-group has-error\">\n\t<div class=\"alert alert-danger\">@w.ErrorMsg</div>\n} else {\n\t<div class=\"form-group\">\n}\n\n\t<label for=\"@w.Name\">@w.Label</label>\n\t<input type=\"text\" name=\"@w.Name\" class=\"form-control\" id=\"@w.Name\" placeholder=\"@w.PlaceHolder\" value=\"@w.Value\">\n</div>"
+
+// Badtag renders cases/badtag.gohtml.
+type Badtag struct {
+	w *gorazor.Widget
+}
+
+func New_Badtag(w *gorazor.Widget) templates.Template {
+	return &Badtag{
+		w: w,
+	}
+}
+
+func (t *Badtag) Name() string {
+	return "badtag.gohtml"
+}
+
+func (t *Badtag) Path() string {
+	return "cases/badtag.gohtml"
+}
+
+func (t *Badtag) Source() string {
+	return source_Badtag
+}
+
+func (t *Badtag) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Badtag) Render(c templates.RenderContext) error {
+	return render_Badtag(c, t.w)
+}
+
+func render_Badtag(c templates.RenderContext, w *gorazor.Widget) error {
 	if w.ErrorMsg != "" {
 
-		_buffer.WriteString("<div class=\"form-group has-error\">\n\t<div class=\"alert alert-danger\">")
-		_buffer.WriteString(gorazor.HTMLEscape(w.ErrorMsg))
-		_buffer.WriteString("</div>")
+		if _, err := c.WriteString("<div class=\"form-group has-error\">\n\t<div class=\"alert alert-danger\">"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(w.ErrorMsg)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("</div>"); err != nil {
+			return err
+		}
 	} else {
 
-		_buffer.WriteString("<div class=\"form-group\">")
-	}
-	_buffer.WriteString("\n\n\t<label for=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(w.Name))
-	_buffer.WriteString("\">")
-	_buffer.WriteString(gorazor.HTMLEscape(w.Label))
-	_buffer.WriteString("</label>\n\t<input type=\"text\" name=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(w.Name))
-	_buffer.WriteString("\" class=\"form-control\" id=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(w.Name))
-	_buffer.WriteString("\" placeholder=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(w.PlaceHolder))
-	_buffer.WriteString("\" value=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(w.Value))
-	_buffer.WriteString("\">\n</div>")
-
-	return _buffer.String()
+		if _, err := c.WriteString("<div class=\"form-group\">"); err != nil {
+			return err
+		}
+	}
+	if _, err := c.WriteString("\n\n\t<label for=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.Name)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\">"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.Label)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</label>\n\t<input type=\"text\" name=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.Name)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\" class=\"form-control\" id=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.Name)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\" placeholder=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.PlaceHolder)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\" value=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(w.Value)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\">\n</div>"); err != nil {
+		return err
+	}
+
+	return nil
 }
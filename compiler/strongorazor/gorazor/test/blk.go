@@ -1,11 +1,41 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Blk() string {
-	var _buffer bytes.Buffer
+const source_Blk = "@{\n}\n"
 
-	return _buffer.String()
+// Blk renders cases/blk.gohtml.
+type Blk struct {
+}
+
+func New_Blk() templates.Template {
+	return &Blk{}
+}
+
+func (t *Blk) Name() string {
+	return "blk.gohtml"
+}
+
+func (t *Blk) Path() string {
+	return "cases/blk.gohtml"
+}
+
+func (t *Blk) Source() string {
+	return source_Blk
+}
+
+func (t *Blk) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Blk) Render(c templates.RenderContext) error {
+	return render_Blk(c)
+}
+
+func render_Blk(c templates.RenderContext) error {
+
+	return nil
 }
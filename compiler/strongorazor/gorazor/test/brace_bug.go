@@ -1,21 +1,55 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Brace_bug() string {
-	var _buffer bytes.Buffer
+const source_Brace_bug = "@{\n}\n\n@{\n    isActive := func(name string) {\n        if active == name {\n            <li class=\"active\">\n        }else{\n            <li>\n        }\n    }\n}\n"
+
+// Brace_bug renders cases/brace_bug.gohtml.
+type Brace_bug struct {
+}
+
+func New_Brace_bug() templates.Template {
+	return &Brace_bug{}
+}
+
+func (t *Brace_bug) Name() string {
+	return "brace_bug.gohtml"
+}
+
+func (t *Brace_bug) Path() string {
+	return "cases/brace_bug.gohtml"
+}
+
+func (t *Brace_bug) Source() string {
+	return source_Brace_bug
+}
+
+func (t *Brace_bug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Brace_bug) Render(c templates.RenderContext) error {
+	return render_Brace_bug(c)
+}
+
+func render_Brace_bug(c templates.RenderContext) error {
 
 	isActive := func(name string) {
 		if active == name {
 
-			_buffer.WriteString("<li class=\"active\">\n        ")
+			if _, err := c.WriteString("<li class=\"active\">\n        "); err != nil {
+				return err
+			}
 		} else {
 
-			_buffer.WriteString("<li>\n        ")
+			if _, err := c.WriteString("<li>\n        "); err != nil {
+				return err
+			}
 		}
 	}
 
-	return _buffer.String()
+	return nil
 }
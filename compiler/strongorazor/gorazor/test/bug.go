@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Bug() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<html>\n  <head>\n    <title>Title</title>\n  </head>\n\n  <body>\n  Body\n  </body>\n</html>")
+const source_Bug = "<html>\n  <head>\n    <title>Title</title>\n  </head>\n\n  <body>\n  Body\n  </body>\n</html>"
 
-	return _buffer.String()
+// Bug renders cases/bug.gohtml.
+type Bug struct {
+}
+
+func New_Bug() templates.Template {
+	return &Bug{}
+}
+
+func (t *Bug) Name() string {
+	return "bug.gohtml"
+}
+
+func (t *Bug) Path() string {
+	return "cases/bug.gohtml"
+}
+
+func (t *Bug) Source() string {
+	return source_Bug
+}
+
+func (t *Bug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Bug) Render(c templates.RenderContext) error {
+	return render_Bug(c)
+}
+
+func render_Bug(c templates.RenderContext) error {
+	if _, err := c.WriteString("<html>\n  <head>\n    <title>Title</title>\n  </head>\n\n  <body>\n  Body\n  </body>\n</html>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Bug34() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("value=\\\"<?= h(aabasdf\\Admin\\Document::$asdf) ?>\\\"/>\\n")
+const source_Bug34 = "value=\\\"<?= h(aabasdf\\Admin\\Document::$asdf) ?>\\\"/>\\n"
 
-	return _buffer.String()
+// Bug34 renders cases/bug34.gohtml.
+type Bug34 struct {
+}
+
+func New_Bug34() templates.Template {
+	return &Bug34{}
+}
+
+func (t *Bug34) Name() string {
+	return "bug34.gohtml"
+}
+
+func (t *Bug34) Path() string {
+	return "cases/bug34.gohtml"
+}
+
+func (t *Bug34) Source() string {
+	return source_Bug34
+}
+
+func (t *Bug34) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Bug34) Render(c templates.RenderContext) error {
+	return render_Bug34(c)
+}
+
+func render_Bug34(c templates.RenderContext) error {
+	if _, err := c.WriteString("value=\\\"<?= h(aabasdf\\Admin\\Document::$asdf) ?>\\\"/>\\n"); err != nil {
+		return err
+	}
+
+	return nil
 }
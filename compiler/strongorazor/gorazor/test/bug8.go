@@ -1,15 +1,53 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Bug8(l *Locale) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n<span>")
-	_buffer.WriteString(gorazor.HTMLEscape(l.T("for")))
-	_buffer.WriteString("</span>")
+const source_Bug8 = "@{\n\tvar l *Locale\n}\n<span>@l.T(\"for\")</span>"
 
-	return _buffer.String()
+// Bug8 renders cases/bug8.gohtml.
+type Bug8 struct {
+	l *Locale
+}
+
+func New_Bug8(l *Locale) templates.Template {
+	return &Bug8{
+		l: l,
+	}
+}
+
+func (t *Bug8) Name() string {
+	return "bug8.gohtml"
+}
+
+func (t *Bug8) Path() string {
+	return "cases/bug8.gohtml"
+}
+
+func (t *Bug8) Source() string {
+	return source_Bug8
+}
+
+func (t *Bug8) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Bug8) Render(c templates.RenderContext) error {
+	return render_Bug8(c, t.l)
+}
+
+func render_Bug8(c templates.RenderContext, l *Locale) error {
+	if _, err := c.WriteString("\n<span>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(l.T("for"))); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</span>"); err != nil {
+		return err
+	}
+
+	return nil
 }
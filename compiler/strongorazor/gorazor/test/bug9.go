@@ -1,15 +1,53 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Bug9(l *Locale) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n<span>")
-	_buffer.WriteString(gorazor.HTMLEscape(l.T(`for`)))
-	_buffer.WriteString("</span>")
+const source_Bug9 = "@{\n    var l *Locale\n}\n<span>@l.T(`for`)</span>"
 
-	return _buffer.String()
+// Bug9 renders cases/bug9.gohtml.
+type Bug9 struct {
+	l *Locale
+}
+
+func New_Bug9(l *Locale) templates.Template {
+	return &Bug9{
+		l: l,
+	}
+}
+
+func (t *Bug9) Name() string {
+	return "bug9.gohtml"
+}
+
+func (t *Bug9) Path() string {
+	return "cases/bug9.gohtml"
+}
+
+func (t *Bug9) Source() string {
+	return source_Bug9
+}
+
+func (t *Bug9) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Bug9) Render(c templates.RenderContext) error {
+	return render_Bug9(c, t.l)
+}
+
+func render_Bug9(c templates.RenderContext, l *Locale) error {
+	if _, err := c.WriteString("\n<span>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(l.T(`for`))); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</span>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,11 +1,41 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Codeblock() string {
-	var _buffer bytes.Buffer
+const source_Codeblock = "@{\n\timport (\n\t)\n}\n"
 
-	return _buffer.String()
+// Codeblock renders cases/codeblock.gohtml.
+type Codeblock struct {
+}
+
+func New_Codeblock() templates.Template {
+	return &Codeblock{}
+}
+
+func (t *Codeblock) Name() string {
+	return "codeblock.gohtml"
+}
+
+func (t *Codeblock) Path() string {
+	return "cases/codeblock.gohtml"
+}
+
+func (t *Codeblock) Source() string {
+	return source_Codeblock
+}
+
+func (t *Codeblock) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Codeblock) Render(c templates.RenderContext) error {
+	return render_Codeblock(c)
+}
+
+func render_Codeblock(c templates.RenderContext) error {
+
+	return nil
 }
@@ -1,14 +1,46 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Comment() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n\n\n<p>hello </p>")
+const source_Comment = "@{\n}\n\n@* should be ignored *@\n\n<p>hello </p>\n@{\n@*\nthis is multiple line\n*@\nhello\n}\n"
+
+// Comment renders cases/comment.gohtml.
+type Comment struct {
+}
+
+func New_Comment() templates.Template {
+	return &Comment{}
+}
+
+func (t *Comment) Name() string {
+	return "comment.gohtml"
+}
+
+func (t *Comment) Path() string {
+	return "cases/comment.gohtml"
+}
+
+func (t *Comment) Source() string {
+	return source_Comment
+}
+
+func (t *Comment) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Comment) Render(c templates.RenderContext) error {
+	return render_Comment(c)
+}
+
+func render_Comment(c templates.RenderContext) error {
+	if _, err := c.WriteString("\n\n\n\n<p>hello </p>"); err != nil {
+		return err
+	}
 
 	hello
 
-	return _buffer.String()
+	return nil
 }
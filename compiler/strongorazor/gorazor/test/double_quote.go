@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Double_quote() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<meta charset=\"utf-8\" />")
+const source_Double_quote = "<meta charset=\"utf-8\" />"
 
-	return _buffer.String()
+// Double_quote renders cases/double_quote.gohtml.
+type Double_quote struct {
+}
+
+func New_Double_quote() templates.Template {
+	return &Double_quote{}
+}
+
+func (t *Double_quote) Name() string {
+	return "double_quote.gohtml"
+}
+
+func (t *Double_quote) Path() string {
+	return "cases/double_quote.gohtml"
+}
+
+func (t *Double_quote) Source() string {
+	return source_Double_quote
+}
+
+func (t *Double_quote) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Double_quote) Render(c templates.RenderContext) error {
+	return render_Double_quote(c)
+}
+
+func render_Double_quote(c templates.RenderContext) error {
+	if _, err := c.WriteString("<meta charset=\"utf-8\" />"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -2,26 +2,78 @@ package cases
 
 import (
 	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	"kp/models"
 	"tpl/admin/layout"
 )
 
-func Edit(u *models.User) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n<div style=\"width: 500px\">\n<form role=\"form\">\n  <div class=\"form-group\">\n    <label for=\"exampleInputEmail1\">名字</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputEmail1\" placeholder=\"Enter email\" value=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-	_buffer.WriteString("\">\n  </div>\n  <div class=\"form-group\">\n    <label for=\"exampleInputPassword1\">电邮</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputPassword1\" placeholder=\"电邮\" value=\"")
-	_buffer.WriteString(gorazor.HTMLEscape(u.Email))
-	_buffer.WriteString("\">\n  </div>\n  <button type=\"submit\" class=\"btn btn-primary\">保存</button>\n  <a href=\"/admin/user\" class=\"btn btn-default pull-right\">返回</a>\n</form>\n</div>")
+const source_Edit = "@{\n\timport (\n\t\t\"tpl/admin/layout/base\"\n\t\t\"kp/models\"\n\t)\n\tvar u *models.User\n}\n<div style=\"width: 500px\">\n<form role=\"form\">\n  <div class=\"form-group\">\n    <label for=\"exampleInputEmail1\">名字</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputEmail1\" placeholder=\"Enter email\" value=\"@u.Name\">\n  </div>\n  <div class=\"form-group\">\n    <label for=\"exampleInputPassword1\">电邮</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputPassword1\" placeholder=\"电邮\" value=\"@u.Email\">\n  </div>\n  <button type=\"submit\" class=\"btn btn-primary\">保存</button>\n  <a href=\"/admin/user\" class=\"btn btn-default pull-right\">返回</a>\n</form>\n</div>\n\n\n@section title {\n\t@:用户管理\n}"
 
-	title := func() string {
-		var _buffer bytes.Buffer
+// Edit renders cases/edit.gohtml.
+type Edit struct {
+	u *models.User
+}
+
+func New_Edit(u *models.User) templates.Template {
+	return &Edit{
+		u: u,
+	}
+}
+
+func (t *Edit) Name() string {
+	return "edit.gohtml"
+}
 
-		_buffer.WriteString("用户管理")
+func (t *Edit) Path() string {
+	return "cases/edit.gohtml"
+}
+
+func (t *Edit) Source() string {
+	return source_Edit
+}
+
+func (t *Edit) GetData(ctx context.Context) error {
+	return nil
+}
 
-		return _buffer.String()
+func (t *Edit) Render(c templates.RenderContext) error {
+	return render_Edit(c, t.u)
+}
+
+func render_Edit(c templates.RenderContext, u *models.User) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
+	if _, err := c.WriteString("\n<div style=\"width: 500px\">\n<form role=\"form\">\n  <div class=\"form-group\">\n    <label for=\"exampleInputEmail1\">名字</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputEmail1\" placeholder=\"Enter email\" value=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\">\n  </div>\n  <div class=\"form-group\">\n    <label for=\"exampleInputPassword1\">电邮</label>\n    <input type=\"email\" class=\"form-control\" id=\"exampleInputPassword1\" placeholder=\"电邮\" value=\""); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(u.Email)); err != nil {
+		return err
 	}
+	if _, err := c.WriteString("\">\n  </div>\n  <button type=\"submit\" class=\"btn btn-primary\">保存</button>\n  <a href=\"/admin/user\" class=\"btn btn-default pull-right\">返回</a>\n</form>\n</div>"); err != nil {
+		return err
+	}
+	title := func(c templates.RenderContext) error {
+
+		if _, err := c.WriteString("用户管理"); err != nil {
+			return err
+		}
 
-	return layout.Base(_buffer.String(), title())
+		return nil
+	}
+
+	var _title bytes.Buffer
+	c.Writer = &_title
+	if err := title(c); err != nil {
+		return err
+	}
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), templates.HTML(_title.String())).Render(c)
 }
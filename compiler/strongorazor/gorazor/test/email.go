@@ -1,15 +1,50 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Email() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<span>rememberingsteve@apple.com ")
-	_buffer.WriteString(gorazor.HTMLEscape(username))
-	_buffer.WriteString("</span>")
+const source_Email = "<span>rememberingsteve@apple.com @username</span>"
 
-	return _buffer.String()
+// Email renders cases/email.gohtml.
+type Email struct {
+}
+
+func New_Email() templates.Template {
+	return &Email{}
+}
+
+func (t *Email) Name() string {
+	return "email.gohtml"
+}
+
+func (t *Email) Path() string {
+	return "cases/email.gohtml"
+}
+
+func (t *Email) Source() string {
+	return source_Email
+}
+
+func (t *Email) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Email) Render(c templates.RenderContext) error {
+	return render_Email(c)
+}
+
+func render_Email(c templates.RenderContext) error {
+	if _, err := c.WriteString("<span>rememberingsteve@apple.com "); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(username)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</span>"); err != nil {
+		return err
+	}
+
+	return nil
 }
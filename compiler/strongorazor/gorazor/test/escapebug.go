@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Escapebug() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<script type=\"text/javascript\">console.log(\"\\n\");</script>")
+const source_Escapebug = "<script type=\"text/javascript\">console.log(\"\\n\");</script>\n"
 
-	return _buffer.String()
+// Escapebug renders cases/escapebug.gohtml.
+type Escapebug struct {
+}
+
+func New_Escapebug() templates.Template {
+	return &Escapebug{}
+}
+
+func (t *Escapebug) Name() string {
+	return "escapebug.gohtml"
+}
+
+func (t *Escapebug) Path() string {
+	return "cases/escapebug.gohtml"
+}
+
+func (t *Escapebug) Source() string {
+	return source_Escapebug
+}
+
+func (t *Escapebug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Escapebug) Render(c templates.RenderContext) error {
+	return render_Escapebug(c)
+}
+
+func render_Escapebug(c templates.RenderContext) error {
+	if _, err := c.WriteString("<script type=\"text/javascript\">console.log(\"\\n\");</script>"); err != nil {
+		return err
+	}
+
+	return nil
 }
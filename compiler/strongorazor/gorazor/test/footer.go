@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Footer() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<div>copyright 2014</div>")
+const source_Footer = "<div>copyright 2014</div>"
 
-	return _buffer.String()
+// Footer renders cases/footer.gohtml.
+type Footer struct {
+}
+
+func New_Footer() templates.Template {
+	return &Footer{}
+}
+
+func (t *Footer) Name() string {
+	return "footer.gohtml"
+}
+
+func (t *Footer) Path() string {
+	return "cases/footer.gohtml"
+}
+
+func (t *Footer) Source() string {
+	return source_Footer
+}
+
+func (t *Footer) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Footer) Render(c templates.RenderContext) error {
+	return render_Footer(c)
+}
+
+func render_Footer(c templates.RenderContext) error {
+	if _, err := c.WriteString("<div>copyright 2014</div>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -3,13 +3,53 @@ package cases
 import (
 	"bytes"
 	"cases/layout"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Forward(content string, err string) string {
-	var _buffer bytes.Buffer
+const source_Forward = "@{\nimport (\n\"cases/layout/base\"\n)\n\nvar content string\nvar err string\n}\n\n\n@{\n//hello word\n/* hello this */\n}\n"
+
+// Forward renders cases/forward.gohtml.
+type Forward struct {
+	content string
+	err     string
+}
+
+func New_Forward(content string, err string) templates.Template {
+	return &Forward{
+		content: content,
+		err:     err,
+	}
+}
+
+func (t *Forward) Name() string {
+	return "forward.gohtml"
+}
+
+func (t *Forward) Path() string {
+	return "cases/forward.gohtml"
+}
+
+func (t *Forward) Source() string {
+	return source_Forward
+}
+
+func (t *Forward) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Forward) Render(c templates.RenderContext) error {
+	return render_Forward(c, t.content, t.err)
+}
+
+func render_Forward(c templates.RenderContext, content string, err string) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
 
 	//hello word
 	/* hello this */
 
-	return layout.Base(_buffer.String(), "", "")
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), "", "").Render(c)
 }
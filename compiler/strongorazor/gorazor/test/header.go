@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Header() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<div>Page Header</div>")
+const source_Header = "<div>Page Header</div>"
 
-	return _buffer.String()
+// Header renders cases/header.gohtml.
+type Header struct {
+}
+
+func New_Header() templates.Template {
+	return &Header{}
+}
+
+func (t *Header) Name() string {
+	return "header.gohtml"
+}
+
+func (t *Header) Path() string {
+	return "cases/header.gohtml"
+}
+
+func (t *Header) Source() string {
+	return source_Header
+}
+
+func (t *Header) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Header) Render(c templates.RenderContext) error {
+	return render_Header(c)
+}
+
+func render_Header(c templates.RenderContext) error {
+	if _, err := c.WriteString("<div>Page Header</div>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,24 @@
+package helper
+
+import (
+	"bytes"
+	"github.com/strongo/templates"
+	. "kp/models"
+)
+
+func Msg(u *User) templates.HTML {
+	var _buffer bytes.Buffer
+
+	username := u.Name
+	if u.Email != "" {
+		username += "(" + u.Email + ")"
+	}
+
+	_buffer.WriteString("\n<div class=\"welcome\">\n<h4>Hello ")
+	_buffer.WriteString(templates.EscapeHTML(username))
+	_buffer.WriteString("</h4>\n\n<div>")
+	_buffer.WriteString(templates.EscapeHTML(templates.HTML(u.Intro)))
+	_buffer.WriteString("</div>\n</div>")
+
+	return templates.HTML(_buffer.String())
+}
@@ -1,7 +1,8 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 	"hello"
 	"huhu"
 	"now"
@@ -9,9 +10,40 @@ import (
 	"this"
 )
 
-func Import() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n\n<p>hello</p>")
+const source_Import = "@{\nimport (\"strconv\")\nimport (\"now\")\nimport \"this\"\nimport (\n    \"hello\"\n    \"huhu\"\n    )\n)\n\n}\n\n\n<p>hello</p>\n"
 
-	return _buffer.String()
+// Import renders cases/import.gohtml.
+type Import struct {
+}
+
+func New_Import() templates.Template {
+	return &Import{}
+}
+
+func (t *Import) Name() string {
+	return "import.gohtml"
+}
+
+func (t *Import) Path() string {
+	return "cases/import.gohtml"
+}
+
+func (t *Import) Source() string {
+	return source_Import
+}
+
+func (t *Import) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Import) Render(c templates.RenderContext) error {
+	return render_Import(c)
+}
+
+func render_Import(c templates.RenderContext) error {
+	if _, err := c.WriteString("\n\n\n<p>hello</p>"); err != nil {
+		return err
+	}
+
+	return nil
 }
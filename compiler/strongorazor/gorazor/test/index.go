@@ -3,40 +3,113 @@ package cases
 import (
 	"bytes"
 	"cases/layout"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	"kp/models"
 )
 
-func Index(users []*models.User, total int, limit int, offset int) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n<h2 class=\"sub-header\">用户总数：")
-	_buffer.WriteString(gorazor.HTMLEscape(gorazor.Itoa(total)))
-	_buffer.WriteString("</h2>\n<div class=\"table-responsive\">\n\t<table class=\"table table-striped\">\n\t\t<thead>\n\t\t\t<tr>\n\t\t\t\t<th>名字</th>\n\t\t\t\t<th>电邮</th>\n\t\t\t\t<th>编辑</th>\n\t\t\t</tr>\n\t\t</thead>\n\t\t<tbody>\n\t\t\t")
-	for _, u := range users {
+const source_Index = "@{\n\timport (\n\t\t\"cases/layout/base\"\n\t\t\"kp/models\"\n\t)\n\tvar users []*models.User\n\tvar total int\n\tvar limit int\n\tvar offset int\n}\n\n<h2 class=\"sub-header\">用户总数：@gorazor.Itoa(total)</h2>\n<div class=\"table-responsive\">\n\t<table class=\"table table-striped\">\n\t\t<thead>\n\t\t\t<tr>\n\t\t\t\t<th>名字</th>\n\t\t\t\t<th>电邮</th>\n\t\t\t\t<th>编辑</th>\n\t\t\t</tr>\n\t\t</thead>\n\t\t<tbody>\n\t\t\t@for _, u := range users {\n\t\t\t<tr>\n\t\t\t\t<td>@u.Name</td>\n\t\t\t\t<td>@u.Email</td>\n\t\t\t\t<td><a href=\"/admin/user/edit?id=@u.ID.Hex()\">编辑</a></td>\n\t\t\t</tr>\n\t\t\t}\n\t\t</tbody>\n\t</table>\n</div>\n\n@section js {\n}\n\n\n@section title {\n\t@:用户管理\n}\n"
 
-		_buffer.WriteString("<tr>\n\t\t\t\t<td>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Name))
-		_buffer.WriteString("</td>\n\t\t\t\t<td>")
-		_buffer.WriteString(gorazor.HTMLEscape(u.Email))
-		_buffer.WriteString("</td>\n\t\t\t\t<td><a href=\"/admin/user/edit?id=")
-		_buffer.WriteString(gorazor.HTMLEscape(u.ID.Hex()))
-		_buffer.WriteString("\">编辑</a></td>\n\t\t\t</tr>")
+// Index renders cases/index.gohtml.
+type Index struct {
+	users  []*models.User
+	total  int
+	limit  int
+	offset int
+}
 
+func New_Index(users []*models.User, total int, limit int, offset int) templates.Template {
+	return &Index{
+		users:  users,
+		total:  total,
+		limit:  limit,
+		offset: offset,
 	}
-	_buffer.WriteString("\n\t\t</tbody>\n\t</table>\n</div>")
+}
+
+func (t *Index) Name() string {
+	return "index.gohtml"
+}
+
+func (t *Index) Path() string {
+	return "cases/index.gohtml"
+}
+
+func (t *Index) Source() string {
+	return source_Index
+}
+
+func (t *Index) GetData(ctx context.Context) error {
+	return nil
+}
 
-	js := func() string {
-		var _buffer bytes.Buffer
-		return _buffer.String()
+func (t *Index) Render(c templates.RenderContext) error {
+	return render_Index(c, t.users, t.total, t.limit, t.offset)
+}
+
+func render_Index(c templates.RenderContext, users []*models.User, total int, limit int, offset int) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
+	if _, err := c.WriteString("\n\n<h2 class=\"sub-header\">用户总数："); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(gorazor.Itoa(total))); err != nil {
+		return err
 	}
+	if _, err := c.WriteString("</h2>\n<div class=\"table-responsive\">\n\t<table class=\"table table-striped\">\n\t\t<thead>\n\t\t\t<tr>\n\t\t\t\t<th>名字</th>\n\t\t\t\t<th>电邮</th>\n\t\t\t\t<th>编辑</th>\n\t\t\t</tr>\n\t\t</thead>\n\t\t<tbody>\n\t\t\t"); err != nil {
+		return err
+	}
+	for _, u := range users {
 
-	title := func() string {
-		var _buffer bytes.Buffer
+		if _, err := c.WriteString("<tr>\n\t\t\t\t<td>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("</td>\n\t\t\t\t<td>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.Email)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("</td>\n\t\t\t\t<td><a href=\"/admin/user/edit?id="); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(u.ID.Hex())); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("\">编辑</a></td>\n\t\t\t</tr>"); err != nil {
+			return err
+		}
 
-		_buffer.WriteString("用户管理")
+	}
+	if _, err := c.WriteString("\n\t\t</tbody>\n\t</table>\n</div>"); err != nil {
+		return err
+	}
+	js := func(c templates.RenderContext) error {
+		return nil
+	}
+	title := func(c templates.RenderContext) error {
+
+		if _, err := c.WriteString("用户管理"); err != nil {
+			return err
+		}
 
-		return _buffer.String()
+		return nil
 	}
 
-	return layout.Base(_buffer.String(), title(), js())
+	var _js bytes.Buffer
+	c.Writer = &_js
+	if err := js(c); err != nil {
+		return err
+	}
+	var _title bytes.Buffer
+	c.Writer = &_title
+	if err := title(c); err != nil {
+		return err
+	}
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), templates.HTML(_title.String()), templates.HTML(_js.String())).Render(c)
 }
@@ -1,16 +1,51 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	"github.com/sunfmin/gorazortests/models"
 )
 
-func Inline_var() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n<body>")
-	_buffer.WriteString(gorazor.HTMLEscape(Hello("Felix Sun", "h1", 30, &models.Author{"Van", 20}, 10)))
-	_buffer.WriteString("\n</body>")
+const source_Inline_var = "@{\n    import (\n        \"github.com/sunfmin/gorazortests/models\"\n    )\n}\n\n<body>\n@Hello(\"Felix Sun\", \"h1\", 30, &models.Author{\"Van\", 20},10)\n</body>\n"
 
-	return _buffer.String()
+// Inline_var renders cases/inline_var.gohtml.
+type Inline_var struct {
+}
+
+func New_Inline_var() templates.Template {
+	return &Inline_var{}
+}
+
+func (t *Inline_var) Name() string {
+	return "inline_var.gohtml"
+}
+
+func (t *Inline_var) Path() string {
+	return "cases/inline_var.gohtml"
+}
+
+func (t *Inline_var) Source() string {
+	return source_Inline_var
+}
+
+func (t *Inline_var) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Inline_var) Render(c templates.RenderContext) error {
+	return render_Inline_var(c)
+}
+
+func render_Inline_var(c templates.RenderContext) error {
+	if _, err := c.WriteString("\n\n<body>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(Hello("Felix Sun", "h1", 30, &models.Author{"Van", 20}, 10))); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n</body>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,15 +1,50 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Keyword() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("BLK(<span>rememberingsteve@apple.com ")
-	_buffer.WriteString(gorazor.HTMLEscape(username))
-	_buffer.WriteString("</span>)BLK")
+const source_Keyword = "BLK(<span>rememberingsteve@apple.com @username</span>)BLK"
 
-	return _buffer.String()
+// Keyword renders cases/keyword.gohtml.
+type Keyword struct {
+}
+
+func New_Keyword() templates.Template {
+	return &Keyword{}
+}
+
+func (t *Keyword) Name() string {
+	return "keyword.gohtml"
+}
+
+func (t *Keyword) Path() string {
+	return "cases/keyword.gohtml"
+}
+
+func (t *Keyword) Source() string {
+	return source_Keyword
+}
+
+func (t *Keyword) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Keyword) Render(c templates.RenderContext) error {
+	return render_Keyword(c)
+}
+
+func render_Keyword(c templates.RenderContext) error {
+	if _, err := c.WriteString("BLK(<span>rememberingsteve@apple.com "); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(username)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</span>)BLK"); err != nil {
+		return err
+	}
+
+	return nil
 }
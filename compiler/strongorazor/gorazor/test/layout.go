@@ -1,19 +1,69 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Layout(body string, title string, side string) string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />")
-	_buffer.WriteString(gorazor.HTMLEscape(title))
-	_buffer.WriteString("\n</head>\n<body>\n<div>")
-	_buffer.WriteString(gorazor.HTMLEscape(body))
-	_buffer.WriteString("</div>\n<div>")
-	_buffer.WriteString(gorazor.HTMLEscape(side))
-	_buffer.WriteString("</div>\n</body>\n</html>")
-
-	return _buffer.String()
+const source_Layout = "@{\n\tvar body string\n\tvar title string\n\tvar side string\n}\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />\n@title\n</head>\n<body>\n<div>@body</div>\n<div>@side</div>\n</body>\n</html>"
+
+// Layout renders cases/layout.gohtml.
+type Layout struct {
+	body  string
+	title string
+	side  string
+}
+
+func New_Layout(body string, title string, side string) templates.Template {
+	return &Layout{
+		body:  body,
+		title: title,
+		side:  side,
+	}
+}
+
+func (t *Layout) Name() string {
+	return "layout.gohtml"
+}
+
+func (t *Layout) Path() string {
+	return "cases/layout.gohtml"
+}
+
+func (t *Layout) Source() string {
+	return source_Layout
+}
+
+func (t *Layout) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Layout) Render(c templates.RenderContext) error {
+	return render_Layout(c, t.body, t.title, t.side)
+}
+
+func render_Layout(c templates.RenderContext, body string, title string, side string) error {
+	if _, err := c.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\" />"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(title)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n</head>\n<body>\n<div>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(body)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</div>\n<div>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(side)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</div>\n</body>\n</html>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,15 +1,48 @@
 package layout
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 	"strconv"
 	"zfw/models"
 )
 
-func Args(objs ...*models.Widget) string {
-	var _buffer bytes.Buffer
+const source_Args = "@{\nimport (\n\"strconv\"\n\"zfw/models\"\n)\nvar objs ...*models.Widget\n}\n\n@{\n  size := strconv.Itoa(12/len(objs))\n}\n"
+
+// Args renders layout/args.gohtml.
+type Args struct {
+	objs []*models.Widget
+}
+
+func New_Args(objs ...*models.Widget) templates.Template {
+	return &Args{
+		objs: objs,
+	}
+}
+
+func (t *Args) Name() string {
+	return "args.gohtml"
+}
+
+func (t *Args) Path() string {
+	return "layout/args.gohtml"
+}
+
+func (t *Args) Source() string {
+	return source_Args
+}
+
+func (t *Args) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Args) Render(c templates.RenderContext) error {
+	return render_Args(c, t.objs...)
+}
+
+func render_Args(c templates.RenderContext, objs ...*models.Widget) error {
 
 	size := strconv.Itoa(12 / len(objs))
 
-	return _buffer.String()
+	return nil
 }
@@ -1,27 +1,85 @@
 package layout
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	"tpl/admin/helper"
 )
 
-func Base(body string, title string, js string) string {
-	var _buffer bytes.Buffer
+const source_Base = "@{\n\timport (\n\t\t\"tpl/admin/helper\"\n\t)\n\tvar body templates.HTML\n\tvar title string\n\tvar js templates.HTML\n}\n@{\n  companyName := \"深圳思品科技有限公司\"\n}\n<!DOCTYPE html>\n<html>\n<head>\n\t<meta charset=\"utf-8\" />\n    <meta http-equiv=\"X-UA-Compatible\" content=\"IE=edge\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n\t<link rel=\"stylesheet\" href=\"/css/bootstrap.min.css\">\n\t<link rel=\"stylesheet\" href=\"/css/dashboard.css\">\n    <!-- HTML5 shim and Respond.js IE8 support of HTML5 elements and media queries -->\n    <!--[if lt IE 9]>\n      <script src=\"https://oss.maxcdn.com/libs/html5shiv/3.7.0/html5shiv.js\"></script>\n      <script src=\"https://oss.maxcdn.com/libs/respond.js/1.4.2/respond.min.js\"></script>\n    <![endif]-->\n\t<title>@title</title>\n</head>\n<body>\n    <div class=\"navbar navbar-inverse navbar-fixed-top\" role=\"navigation\">\n      <div class=\"container-fluid\">\n        <div class=\"navbar-header\">\n          <button type=\"button\" class=\"navbar-toggle\" data-toggle=\"collapse\" data-target=\".navbar-collapse\">\n            <span class=\"sr-only\">Toggle navigation</span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n          </button>\n          <a class=\"navbar-brand\" href=\"http://wethinkwith.com\">@companyName</a>我们在<a href=\"http://www.v2ex.com/t/109162\">招聘</a>\n        </div>\n        <div class=\"navbar-collapse collapse\">\n          <ul class=\"nav navbar-nav navbar-right\">\n            <li><a href=\"/admin/setting\">设置</a></li>\n            <li><a href=\"/admin/help\">帮助</a></li>\n            <li><a href=\"/admin/logout\">退出</a></li>\n          </ul>\n          <form class=\"navbar-form navbar-right\">\n            <input type=\"text\" class=\"form-control\" placeholder=\"搜索...\">\n          </form>\n        </div>\n      </div>\n    </div>\n\n    <div class=\"container-fluid\">\n      <div class=\"row\">\n        <div class=\"col-sm-3 col-md-2 sidebar\">\n\t\t\t@helper.Menu()\n        </div>\n        <div class=\"col-sm-9 col-sm-offset-3 col-md-10 col-md-offset-2 main\">\n          @body\n        </div>\n      </div>\n    </div>\n\t<script src=\"/js/jquery.min.js\"></script>\n\t<script src=\"/js/bootstrap.min.js\"></script>\n\t@js\n  </body>\n</html>\n"
+
+// Base renders layout/base.gohtml.
+type Base struct {
+	body  templates.HTML
+	title string
+	js    templates.HTML
+}
+
+func New_Base(body templates.HTML, title string, js templates.HTML) templates.Template {
+	return &Base{
+		body:  body,
+		title: title,
+		js:    js,
+	}
+}
+
+func (t *Base) Name() string {
+	return "base.gohtml"
+}
+
+func (t *Base) Path() string {
+	return "layout/base.gohtml"
+}
+
+func (t *Base) Source() string {
+	return source_Base
+}
+
+func (t *Base) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Base) Render(c templates.RenderContext) error {
+	return render_Base(c, t.body, t.title, t.js)
+}
+
+func render_Base(c templates.RenderContext, body templates.HTML, title string, js templates.HTML) error {
 
 	companyName := "深圳思品科技有限公司"
 
-	_buffer.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n\t<meta charset=\"utf-8\" />\n    <meta http-equiv=\"X-UA-Compatible\" content=\"IE=edge\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n\t<link rel=\"stylesheet\" href=\"/css/bootstrap.min.css\">\n\t<link rel=\"stylesheet\" href=\"/css/dashboard.css\">\n    <!-- HTML5 shim and Respond.js IE8 support of HTML5 elements and media queries -->\n    <!--[if lt IE 9]>\n      <script src=\"https://oss.maxcdn.com/libs/html5shiv/3.7.0/html5shiv.js\"></script>\n      <script src=\"https://oss.maxcdn.com/libs/respond.js/1.4.2/respond.min.js\"></script>\n    <![endif]-->\n\t<title>")
-	_buffer.WriteString((title))
-	_buffer.WriteString("</title>\n</head>\n<body>\n    <div class=\"navbar navbar-inverse navbar-fixed-top\" role=\"navigation\">\n      <div class=\"container-fluid\">\n        <div class=\"navbar-header\">\n          <button type=\"button\" class=\"navbar-toggle\" data-toggle=\"collapse\" data-target=\".navbar-collapse\">\n            <span class=\"sr-only\">Toggle navigation</span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n          </button>\n          <a class=\"navbar-brand\" href=\"http://wethinkwith.com\">")
-	_buffer.WriteString(gorazor.HTMLEscape(companyName))
-	_buffer.WriteString("</a>我们在<a href=\"http://www.v2ex.com/t/109162\">招聘</a>\n        </div>\n        <div class=\"navbar-collapse collapse\">\n          <ul class=\"nav navbar-nav navbar-right\">\n            <li><a href=\"/admin/setting\">设置</a></li>\n            <li><a href=\"/admin/help\">帮助</a></li>\n            <li><a href=\"/admin/logout\">退出</a></li>\n          </ul>\n          <form class=\"navbar-form navbar-right\">\n            <input type=\"text\" class=\"form-control\" placeholder=\"搜索...\">\n          </form>\n        </div>\n      </div>\n    </div>\n\n    <div class=\"container-fluid\">\n      <div class=\"row\">\n        <div class=\"col-sm-3 col-md-2 sidebar\">\n\t\t\t")
-	_buffer.WriteString((helper.Menu()))
-	_buffer.WriteString("\n        </div>\n        <div class=\"col-sm-9 col-sm-offset-3 col-md-10 col-md-offset-2 main\">\n          ")
-	_buffer.WriteString((body))
-	_buffer.WriteString("\n        </div>\n      </div>\n    </div>\n\t<script src=\"/js/jquery.min.js\"></script>\n\t<script src=\"/js/bootstrap.min.js\"></script>\n\t")
-	_buffer.WriteString((js))
-	_buffer.WriteString("\n  </body>\n</html>")
-
-	return _buffer.String()
+	if _, err := c.WriteString("\n<!DOCTYPE html>\n<html>\n<head>\n\t<meta charset=\"utf-8\" />\n    <meta http-equiv=\"X-UA-Compatible\" content=\"IE=edge\">\n    <meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n\t<link rel=\"stylesheet\" href=\"/css/bootstrap.min.css\">\n\t<link rel=\"stylesheet\" href=\"/css/dashboard.css\">\n    <!-- HTML5 shim and Respond.js IE8 support of HTML5 elements and media queries -->\n    <!--[if lt IE 9]>\n      <script src=\"https://oss.maxcdn.com/libs/html5shiv/3.7.0/html5shiv.js\"></script>\n      <script src=\"https://oss.maxcdn.com/libs/respond.js/1.4.2/respond.min.js\"></script>\n    <![endif]-->\n\t<title>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(title)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</title>\n</head>\n<body>\n    <div class=\"navbar navbar-inverse navbar-fixed-top\" role=\"navigation\">\n      <div class=\"container-fluid\">\n        <div class=\"navbar-header\">\n          <button type=\"button\" class=\"navbar-toggle\" data-toggle=\"collapse\" data-target=\".navbar-collapse\">\n            <span class=\"sr-only\">Toggle navigation</span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n            <span class=\"icon-bar\"></span>\n          </button>\n          <a class=\"navbar-brand\" href=\"http://wethinkwith.com\">"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(companyName)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</a>我们在<a href=\"http://www.v2ex.com/t/109162\">招聘</a>\n        </div>\n        <div class=\"navbar-collapse collapse\">\n          <ul class=\"nav navbar-nav navbar-right\">\n            <li><a href=\"/admin/setting\">设置</a></li>\n            <li><a href=\"/admin/help\">帮助</a></li>\n            <li><a href=\"/admin/logout\">退出</a></li>\n          </ul>\n          <form class=\"navbar-form navbar-right\">\n            <input type=\"text\" class=\"form-control\" placeholder=\"搜索...\">\n          </form>\n        </div>\n      </div>\n    </div>\n\n    <div class=\"container-fluid\">\n      <div class=\"row\">\n        <div class=\"col-sm-3 col-md-2 sidebar\">\n\t\t\t"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(helper.Menu())); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n        </div>\n        <div class=\"col-sm-9 col-sm-offset-3 col-md-10 col-md-offset-2 main\">\n          "); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(body)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n        </div>\n      </div>\n    </div>\n\t<script src=\"/js/jquery.min.js\"></script>\n\t<script src=\"/js/bootstrap.min.js\"></script>\n\t"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(js)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n  </body>\n</html>"); err != nil {
+		return err
+	}
+
+	return nil
 }
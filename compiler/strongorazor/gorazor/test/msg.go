@@ -1,13 +1,45 @@
 package cases
 
 import (
-	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	. "kp/models"
 )
 
-func Msg(u *User) string {
-	var _buffer bytes.Buffer
+const source_Msg = "@{\n\timport (\n\t\t. \"kp/models\"\n\t)\n\tvar u *User\n}\n\n\n@{\n\tgetName := func (u *User) string {\n\t\treturn \"(\" + u.Name + \")\" \n\t}\n\n\tvar username string\n\tif u.Email != \"\" {\n\t\tusername = getName(u) + \"(\" + u.Email + \")\"\n\t}\n}\n<div class=\"welcome\">\n<h4>Hello @username</h4>\n\n<div>@templates.HTML(u.Intro)</div>\n</div>\n"
+
+// Msg renders cases/msg.gohtml.
+type Msg struct {
+	u *User
+}
+
+func New_Msg(u *User) templates.Template {
+	return &Msg{
+		u: u,
+	}
+}
+
+func (t *Msg) Name() string {
+	return "msg.gohtml"
+}
+
+func (t *Msg) Path() string {
+	return "cases/msg.gohtml"
+}
+
+func (t *Msg) Source() string {
+	return source_Msg
+}
+
+func (t *Msg) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Msg) Render(c templates.RenderContext) error {
+	return render_Msg(c, t.u)
+}
+
+func render_Msg(c templates.RenderContext, u *User) error {
 
 	getName := func(u *User) string {
 		return "(" + u.Name + ")"
@@ -18,11 +50,21 @@ func Msg(u *User) string {
 		username = getName(u) + "(" + u.Email + ")"
 	}
 
-	_buffer.WriteString("\n<div class=\"welcome\">\n<h4>Hello ")
-	_buffer.WriteString(gorazor.HTMLEscape(username))
-	_buffer.WriteString("</h4>\n\n<div>")
-	_buffer.WriteString((u.Intro))
-	_buffer.WriteString("</div>\n</div>")
+	if _, err := c.WriteString("\n<div class=\"welcome\">\n<h4>Hello "); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(username)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</h4>\n\n<div>"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(templates.HTML(u.Intro))); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</div>\n</div>"); err != nil {
+		return err
+	}
 
-	return _buffer.String()
+	return nil
 }
@@ -1,12 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Quote() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("<html>'text'</html>")
+const source_Quote = "<html>'text'</html>\n"
 
-	return _buffer.String()
+// Quote renders cases/quote.gohtml.
+type Quote struct {
+}
+
+func New_Quote() templates.Template {
+	return &Quote{}
+}
+
+func (t *Quote) Name() string {
+	return "quote.gohtml"
+}
+
+func (t *Quote) Path() string {
+	return "cases/quote.gohtml"
+}
+
+func (t *Quote) Source() string {
+	return source_Quote
+}
+
+func (t *Quote) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Quote) Render(c templates.RenderContext) error {
+	return render_Quote(c)
+}
+
+func render_Quote(c templates.RenderContext) error {
+	if _, err := c.WriteString("<html>'text'</html>"); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -1,88 +1,190 @@
 package cases
 
 import (
-	"bytes"
+	"context"
 	"dm"
-	"github.com/sipin/gorazor/gorazor"
+	"github.com/strongo/templates"
 	"zfw/models"
 	. "zfw/tplhelper"
 )
 
-func Scope(obj *models.Widget) string {
-	var _buffer bytes.Buffer
+const source_Scope = "@{\nimport (\n\"zfw/models\"\n. \"zfw/tplhelper\"\n\"dm\"\n)\nvar obj *models.Widget\n}\n\n@{\ndata, dmType := dm.GetData(obj.PlaceHolder)\n\nif dmType == \"simple\" {\nobj.StringList = data.([]string)\n<div>@templates.HTML(SelectPk(obj))</div>\n} else {\nnode := data.(*dm.DMTree)\n<div class=\"form-group @GetErrorClass(obj)\">\n  <label for=\"@obj.Name\" class=\"col-sm-2 control-label\">@obj.Label</label>\n  <div class=\"col-sm-10\">\n    <select class=\"form-control\" name=\"@obj.Name\" @BoolStr(obj.Disabled, \"disabled\")>\n      @for _, option := range node.Keys{\n      if values, ok := node.Values[option]; ok {\n      <optgroup label=\"@option\">\n        @for _, value := range values{\n        if value == obj.Value{\n        <option selected>@value</option>\n        }else{\n        <option>@value</option>\n        }\n        }\n      </optgroup>\n      } else {\n      if option == obj.Value{\n      <option selected>@option</option>\n      }else{\n      <option>@option</option>\n      }\n      }\n      }\n    </select>\n    @if obj.ErrorMsg != \"\" {\n    <span class=\"label label-danger\">@obj.ErrorMsg</span>\n    }\n  </div>\n</div>\n\n}\n}\n"
+
+// Scope renders cases/scope.gohtml.
+type Scope struct {
+	obj *models.Widget
+}
+
+func New_Scope(obj *models.Widget) templates.Template {
+	return &Scope{
+		obj: obj,
+	}
+}
+
+func (t *Scope) Name() string {
+	return "scope.gohtml"
+}
+
+func (t *Scope) Path() string {
+	return "cases/scope.gohtml"
+}
+
+func (t *Scope) Source() string {
+	return source_Scope
+}
+
+func (t *Scope) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Scope) Render(c templates.RenderContext) error {
+	return render_Scope(c, t.obj)
+}
+
+func render_Scope(c templates.RenderContext, obj *models.Widget) error {
 
 	data, dmType := dm.GetData(obj.PlaceHolder)
 
 	if dmType == "simple" {
 		obj.StringList = data.([]string)
 
-		_buffer.WriteString("<div>")
-		_buffer.WriteString((SelectPk(obj)))
-		_buffer.WriteString("</div>")
+		if _, err := c.WriteString("<div>"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(templates.HTML(SelectPk(obj)))); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("</div>"); err != nil {
+			return err
+		}
 
 	} else {
 		node := data.(*dm.DMTree)
 
-		_buffer.WriteString("<div class=\"form-group ")
-		_buffer.WriteString(gorazor.HTMLEscape(GetErrorClass(obj)))
-		_buffer.WriteString("\">\n  <label for=\"")
-		_buffer.WriteString(gorazor.HTMLEscape(obj.Name))
-		_buffer.WriteString("\" class=\"col-sm-2 control-label\">")
-		_buffer.WriteString(gorazor.HTMLEscape(obj.Label))
-		_buffer.WriteString("</label>\n  <div class=\"col-sm-10\">\n    <select class=\"form-control\" name=\"")
-		_buffer.WriteString(gorazor.HTMLEscape(obj.Name))
-		_buffer.WriteString("\" ")
-		_buffer.WriteString(gorazor.HTMLEscape(BoolStr(obj.Disabled, "disabled")))
-		_buffer.WriteString(">\n      ")
+		if _, err := c.WriteString("<div class=\"form-group "); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(GetErrorClass(obj))); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("\">\n  <label for=\""); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(obj.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("\" class=\"col-sm-2 control-label\">"); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(obj.Label)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("</label>\n  <div class=\"col-sm-10\">\n    <select class=\"form-control\" name=\""); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(obj.Name)); err != nil {
+			return err
+		}
+		if _, err := c.WriteString("\" "); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(templates.EscapeHTML(BoolStr(obj.Disabled, "disabled"))); err != nil {
+			return err
+		}
+		if _, err := c.WriteString(">\n      "); err != nil {
+			return err
+		}
 		for _, option := range node.Keys {
 			if values, ok := node.Values[option]; ok {
 
-				_buffer.WriteString("<optgroup label=\"")
-				_buffer.WriteString(gorazor.HTMLEscape(option))
-				_buffer.WriteString("\">\n        ")
+				if _, err := c.WriteString("<optgroup label=\""); err != nil {
+					return err
+				}
+				if _, err := c.WriteString(templates.EscapeHTML(option)); err != nil {
+					return err
+				}
+				if _, err := c.WriteString("\">\n        "); err != nil {
+					return err
+				}
 				for _, value := range values {
 					if value == obj.Value {
 
-						_buffer.WriteString("<option selected>")
-						_buffer.WriteString(gorazor.HTMLEscape(value))
-						_buffer.WriteString("</option>")
+						if _, err := c.WriteString("<option selected>"); err != nil {
+							return err
+						}
+						if _, err := c.WriteString(templates.EscapeHTML(value)); err != nil {
+							return err
+						}
+						if _, err := c.WriteString("</option>"); err != nil {
+							return err
+						}
 
 					} else {
 
-						_buffer.WriteString("<option>")
-						_buffer.WriteString(gorazor.HTMLEscape(value))
-						_buffer.WriteString("</option>")
+						if _, err := c.WriteString("<option>"); err != nil {
+							return err
+						}
+						if _, err := c.WriteString(templates.EscapeHTML(value)); err != nil {
+							return err
+						}
+						if _, err := c.WriteString("</option>"); err != nil {
+							return err
+						}
 
 					}
 				}
-				_buffer.WriteString("\n      </optgroup>")
+				if _, err := c.WriteString("\n      </optgroup>"); err != nil {
+					return err
+				}
 
 			} else {
 				if option == obj.Value {
 
-					_buffer.WriteString("<option selected>")
-					_buffer.WriteString(gorazor.HTMLEscape(option))
-					_buffer.WriteString("</option>")
+					if _, err := c.WriteString("<option selected>"); err != nil {
+						return err
+					}
+					if _, err := c.WriteString(templates.EscapeHTML(option)); err != nil {
+						return err
+					}
+					if _, err := c.WriteString("</option>"); err != nil {
+						return err
+					}
 
 				} else {
 
-					_buffer.WriteString("<option>")
-					_buffer.WriteString(gorazor.HTMLEscape(option))
-					_buffer.WriteString("</option>")
+					if _, err := c.WriteString("<option>"); err != nil {
+						return err
+					}
+					if _, err := c.WriteString(templates.EscapeHTML(option)); err != nil {
+						return err
+					}
+					if _, err := c.WriteString("</option>"); err != nil {
+						return err
+					}
 
 				}
 			}
 		}
-		_buffer.WriteString("\n    </select>\n    ")
+		if _, err := c.WriteString("\n    </select>\n    "); err != nil {
+			return err
+		}
 		if obj.ErrorMsg != "" {
 
-			_buffer.WriteString("<span class=\"label label-danger\">")
-			_buffer.WriteString(gorazor.HTMLEscape(obj.ErrorMsg))
-			_buffer.WriteString("</span>")
+			if _, err := c.WriteString("<span class=\"label label-danger\">"); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(templates.EscapeHTML(obj.ErrorMsg)); err != nil {
+				return err
+			}
+			if _, err := c.WriteString("</span>"); err != nil {
+				return err
+			}
 
 		}
-		_buffer.WriteString("\n  </div>\n</div>")
+		if _, err := c.WriteString("\n  </div>\n</div>"); err != nil {
+			return err
+		}
 	}
 
-	return _buffer.String()
+	return nil
 }
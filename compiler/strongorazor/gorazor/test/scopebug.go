@@ -1,14 +1,47 @@
 package cases
 
 import (
-	"bytes"
+	"context"
 	"dm"
+	"github.com/strongo/templates"
 	"zfw/models"
 	. "zfw/tplhelper"
 )
 
-func Scopebug(obj *models.Widget) string {
-	var _buffer bytes.Buffer
+const source_Scopebug = "@{\n\timport (\n\t\t\"zfw/models\"\n\t\t. \"zfw/tplhelper\"\n\t\t\"dm\"\n\t)\n\tvar obj *models.Widget\n}\n\n@{\n        @if 1 == 2 {\n\t} else {\n\t\tvalues := []int{}\n\t\t\t@for _, v := range values {\n\t\t\t@if v, ok := v.(type); ok {\n\t\t\t\t<a>\n\t\t\t\t\t@for _, v := range values {\n\t\t\t\t        }\n\t\t\t\t</a>\n\t\t\t}  else {\n\n\t\t        }\n\t\t}\n\t}\n}\n"
+
+// Scopebug renders cases/scopebug.gohtml.
+type Scopebug struct {
+	obj *models.Widget
+}
+
+func New_Scopebug(obj *models.Widget) templates.Template {
+	return &Scopebug{
+		obj: obj,
+	}
+}
+
+func (t *Scopebug) Name() string {
+	return "scopebug.gohtml"
+}
+
+func (t *Scopebug) Path() string {
+	return "cases/scopebug.gohtml"
+}
+
+func (t *Scopebug) Source() string {
+	return source_Scopebug
+}
+
+func (t *Scopebug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Scopebug) Render(c templates.RenderContext) error {
+	return render_Scopebug(c, t.obj)
+}
+
+func render_Scopebug(c templates.RenderContext, obj *models.Widget) error {
 
 	if 1 == 2 {
 	} else {
@@ -16,10 +49,14 @@ func Scopebug(obj *models.Widget) string {
 		for _, v := range values {
 			if v, ok := v.(type); ok {
 
-				_buffer.WriteString("<a>\n\t\t\t\t\t")
+				if _, err := c.WriteString("<a>\n\t\t\t\t\t"); err != nil {
+					return err
+				}
 				for _, v := range values {
 				}
-				_buffer.WriteString("\n\t\t\t\t</a>")
+				if _, err := c.WriteString("\n\t\t\t\t</a>"); err != nil {
+					return err
+				}
 
 			} else {
 
@@ -27,5 +64,5 @@ func Scopebug(obj *models.Widget) string {
 		}
 	}
 
-	return _buffer.String()
+	return nil
 }
@@ -1,19 +1,51 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Section_comment_bug() string {
-	var _buffer bytes.Buffer
-	_buffer.WriteString("\n\n<a>\n    <!-- comment -->\n</a>")
+const source_Section_comment_bug = "@{\n\n}\n\n<a>\n    <!-- comment -->\n</a>\n\n@section side {\n    <!-- comment -->\n    plain text\n}\n"
 
-	side := func() string {
-		var _buffer bytes.Buffer
+// Section_comment_bug renders cases/section_comment_bug.gohtml.
+type Section_comment_bug struct {
+}
+
+func New_Section_comment_bug() templates.Template {
+	return &Section_comment_bug{}
+}
+
+func (t *Section_comment_bug) Name() string {
+	return "section_comment_bug.gohtml"
+}
+
+func (t *Section_comment_bug) Path() string {
+	return "cases/section_comment_bug.gohtml"
+}
+
+func (t *Section_comment_bug) Source() string {
+	return source_Section_comment_bug
+}
+
+func (t *Section_comment_bug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Section_comment_bug) Render(c templates.RenderContext) error {
+	return render_Section_comment_bug(c)
+}
+
+func render_Section_comment_bug(c templates.RenderContext) error {
+	if _, err := c.WriteString("\n\n<a>\n    <!-- comment -->\n</a>"); err != nil {
+		return err
+	}
+	side := func(c templates.RenderContext) error {
 
-		_buffer.WriteString("<!-- comment -->\n    plain text")
-		return _buffer.String()
+		if _, err := c.WriteString("<!-- comment -->\n    plain text"); err != nil {
+			return err
+		}
+		return nil
 	}
 
-	return _buffer.String(), side()
+	return nil
 }
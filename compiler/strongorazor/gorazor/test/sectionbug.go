@@ -2,25 +2,68 @@ package cases
 
 import (
 	"bytes"
-	"github.com/sipin/gorazor/gorazor"
+	"context"
+	"github.com/strongo/templates"
 	"kp/models"
 	"tpl/admin/layout"
 )
 
-func Sectionbug() string {
-	var _buffer bytes.Buffer
+const source_Sectionbug = "@{\nimport (\n\"tpl/admin/layout/base\"\n\"kp/models\"\n)\n}\n\n\n@section js {\n @for _, jsFile := range ctx.GetJS() {\n <script src=\"@jsFile\"></script>\n }\n}\n"
 
-	js := func() string {
-		var _buffer bytes.Buffer
+// Sectionbug renders cases/sectionbug.gohtml.
+type Sectionbug struct {
+}
+
+func New_Sectionbug() templates.Template {
+	return &Sectionbug{}
+}
+
+func (t *Sectionbug) Name() string {
+	return "sectionbug.gohtml"
+}
+
+func (t *Sectionbug) Path() string {
+	return "cases/sectionbug.gohtml"
+}
+
+func (t *Sectionbug) Source() string {
+	return source_Sectionbug
+}
+
+func (t *Sectionbug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Sectionbug) Render(c templates.RenderContext) error {
+	return render_Sectionbug(c)
+}
+
+func render_Sectionbug(c templates.RenderContext) error {
+	_writer := c.Writer
+	var _body bytes.Buffer
+	c.Writer = &_body
+	js := func(c templates.RenderContext) error {
 		for _, jsFile := range ctx.GetJS() {
 
-			_buffer.WriteString("<script src=\"")
-			_buffer.WriteString(gorazor.HTMLEscape(jsFile))
-			_buffer.WriteString("\"></script>")
+			if _, err := c.WriteString("<script src=\""); err != nil {
+				return err
+			}
+			if _, err := c.WriteString(templates.EscapeHTML(jsFile)); err != nil {
+				return err
+			}
+			if _, err := c.WriteString("\"></script>"); err != nil {
+				return err
+			}
 
 		}
-		return _buffer.String()
+		return nil
 	}
 
-	return layout.Base(_buffer.String(), js())
+	var _js bytes.Buffer
+	c.Writer = &_js
+	if err := js(c); err != nil {
+		return err
+	}
+	c.Writer = _writer
+	return layout.New_Base(templates.HTML(_body.String()), templates.HTML(_js.String())).Render(c)
 }
@@ -1,15 +1,48 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 	"strconv"
 	"zfw/models"
 )
 
-func Slashbug(objs ...*models.Widget) string {
-	var _buffer bytes.Buffer
+const source_Slashbug = "@{\nimport (\n\"strconv\"\n\"zfw/models\"\n)\n   var objs ...*models.Widget\n}\n\n@{\n   size := strconv.Itoa(12/len(objs))\n}\n"
+
+// Slashbug renders cases/slashbug.gohtml.
+type Slashbug struct {
+	objs []*models.Widget
+}
+
+func New_Slashbug(objs ...*models.Widget) templates.Template {
+	return &Slashbug{
+		objs: objs,
+	}
+}
+
+func (t *Slashbug) Name() string {
+	return "slashbug.gohtml"
+}
+
+func (t *Slashbug) Path() string {
+	return "cases/slashbug.gohtml"
+}
+
+func (t *Slashbug) Source() string {
+	return source_Slashbug
+}
+
+func (t *Slashbug) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Slashbug) Render(c templates.RenderContext) error {
+	return render_Slashbug(c, t.objs...)
+}
+
+func render_Slashbug(c templates.RenderContext, objs ...*models.Widget) error {
 
 	size := strconv.Itoa(12 / len(objs))
 
-	return _buffer.String()
+	return nil
 }
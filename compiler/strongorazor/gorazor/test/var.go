@@ -1,11 +1,44 @@
 package cases
 
 import (
-	"bytes"
+	"context"
+	"github.com/strongo/templates"
 )
 
-func Var(totalMessage int) string {
-	var _buffer bytes.Buffer
+const source_Var = "@{\n\tvar totalMessage int\n}\n"
 
-	return _buffer.String()
+// Var renders cases/var.gohtml.
+type Var struct {
+	totalMessage int
+}
+
+func New_Var(totalMessage int) templates.Template {
+	return &Var{
+		totalMessage: totalMessage,
+	}
+}
+
+func (t *Var) Name() string {
+	return "var.gohtml"
+}
+
+func (t *Var) Path() string {
+	return "cases/var.gohtml"
+}
+
+func (t *Var) Source() string {
+	return source_Var
+}
+
+func (t *Var) GetData(ctx context.Context) error {
+	return nil
+}
+
+func (t *Var) Render(c templates.RenderContext) error {
+	return render_Var(c, t.totalMessage)
+}
+
+func render_Var(c templates.RenderContext, totalMessage int) error {
+
+	return nil
 }
@@ -0,0 +1,18 @@
+package templates
+
+import (
+	"github.com/strongo/templates/inspiration/builtin/html"
+)
+
+// Strings of content from a trusted source. Generated code writes a value of one of these types
+// as it is where the context matches its type and escapes it elsewhere, e.g. HTML is stripped of tags
+// in an attribute and a URL in <script> is quoted as any other string; where it could be unsafe,
+// e.g. HTML in a style attribute, it is replaced with "ZgotmplZ". Helpers and components rendering markup
+// return them to have their output written unescaped.
+type (
+	HTML   = html.HTML   // a document fragment, e.g. `<b>Hi</b>`; not for HTML from a third party or with unclosed tags
+	JS     = html.JS     // an expression, e.g. `(x + y * z())`
+	CSS    = html.CSS    // a stylesheet, rule, declarations or value, e.g. `color: red; margin: 2px`
+	URL    = html.URL    // a URL or its part; unlike other strings it may use a scheme like javascript:
+	Srcset = html.Srcset // a srcset attribute value, e.g. `a.png 1x, b.png 2x`
+)
@@ -0,0 +1,207 @@
+package templates
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// DataCache caches data of components between renders, e.g. authors loaded from a DataProvider.
+// Data older than TTL is stale: it is returned right away while a background refresh gets fresh data.
+// Data older than TTL+StaleTTL is fetched again before it is returned.
+// Concurrent fetches of a key are coalesced. A nil cache caches nothing.
+// The zero value is ready to use: it coalesces fetches but does not cache data until TTL is set.
+//
+// Entries are kept until they are fetched again, so keys should be from a bounded set.
+type DataCache struct {
+	TTL         time.Duration // how long data is fresh
+	StaleTTL    time.Duration // how long stale data is returned while it is refreshed
+	NegativeTTL time.Duration // how long fetch errors are cached; not cached if 0
+	Jitter      float64       // fraction of TTLs randomly taken off each entry so that entries do not expire at once
+	Clock       Clock         // SystemClock if nil
+
+	mutex   sync.Mutex
+	entries map[interface{}]*dataEntry
+}
+
+// FetchFunc fetches the data of a key from the source.
+type FetchFunc func(ctx context.Context) (interface{}, error)
+
+type dataEntry struct {
+	done       chan struct{} // closed once the first fetch is done
+	value      interface{}
+	err        error
+	fresh      time.Time // the data is fresh until
+	stale      time.Time // the data is returned while it is refreshed until
+	refreshing bool
+}
+
+func NewDataCache(ttl, staleTTL time.Duration) *DataCache {
+	return &DataCache{
+		TTL:      ttl,
+		StaleTTL: staleTTL,
+		Clock:    SystemClock,
+		entries:  make(map[interface{}]*dataEntry),
+	}
+}
+
+// Get returns the data of the key, fetching it if it is not cached or too old.
+// It waits for a fetch of the key already in flight, or returns the error of ctx once it is done.
+func (dc *DataCache) Get(ctx context.Context, key interface{}, fetch FetchFunc) (interface{}, error) {
+	if dc == nil {
+		return fetch(ctx)
+	}
+	dc.mutex.Lock()
+	entry, ok := dc.entries[key]
+	if ok {
+		select {
+		case <-entry.done:
+		default:
+			dc.mutex.Unlock()
+			value, err := dc.wait(ctx, entry)
+			if isContextError(err) && ctx.Err() == nil {
+				// the fetch was cancelled with the context of the caller it was started by, not ours
+				return dc.Get(ctx, key, fetch)
+			}
+			return value, err
+		}
+		if value, ok, err := dc.lookup(key, entry, fetch); ok {
+			dc.mutex.Unlock()
+			return value, err
+		}
+	}
+	entry = &dataEntry{done: make(chan struct{})}
+	dc.add(key, entry)
+	dc.mutex.Unlock()
+
+	value, err := fetch(ctx)
+	dc.mutex.Lock()
+	dc.set(key, entry, value, err)
+	close(entry.done)
+	dc.mutex.Unlock()
+	return value, err
+}
+
+// Lookup returns the cached data of the key and its fetch error without blocking;
+// ok is false if the data has to be fetched.
+// Stale data is refreshed in the background with the fetch function.
+// It lets components batching their fetches, e.g. with a Loader, ask the cache first and Set the data they fetch.
+func (dc *DataCache) Lookup(key interface{}, fetch FetchFunc) (value interface{}, ok bool, err error) {
+	if dc == nil {
+		return nil, false, nil
+	}
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+	entry, ok := dc.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	select {
+	case <-entry.done:
+		return dc.lookup(key, entry, fetch)
+	default:
+		return nil, false, nil
+	}
+}
+
+// Set caches data of the key fetched by the caller, or the error of the fetch.
+func (dc *DataCache) Set(key interface{}, value interface{}, err error) {
+	if dc == nil {
+		return
+	}
+	entry := &dataEntry{done: make(chan struct{})}
+	close(entry.done)
+	dc.mutex.Lock()
+	dc.add(key, entry)
+	dc.set(key, entry, value, err)
+	dc.mutex.Unlock()
+}
+
+// lookup returns fresh or stale data of the fetched entry, refreshing stale data. The mutex is held.
+func (dc *DataCache) lookup(key interface{}, entry *dataEntry, fetch FetchFunc) (value interface{}, ok bool, err error) {
+	now := dc.now()
+	if now.Before(entry.fresh) {
+		return entry.value, true, entry.err
+	}
+	if now.Before(entry.stale) {
+		if !entry.refreshing {
+			entry.refreshing = true
+			Go(context.Background(), PriorityBelowTheFold, func(ctx context.Context) {
+				dc.refresh(ctx, key, entry, fetch)
+			})
+		}
+		return entry.value, true, entry.err
+	}
+	return nil, false, nil
+}
+
+// refresh fetches fresh data of the stale entry. If the fetch fails the stale data is kept.
+func (dc *DataCache) refresh(ctx context.Context, key interface{}, entry *dataEntry, fetch FetchFunc) {
+	value, err := fetch(ctx)
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+	entry.refreshing = false
+	if err == nil && dc.entries[key] == entry {
+		dc.set(key, entry, value, nil)
+	}
+}
+
+// set stores the fetched data in the entry, or forgets the entry if the error is not to be cached.
+// The mutex is held.
+func (dc *DataCache) set(key interface{}, entry *dataEntry, value interface{}, err error) {
+	entry.value, entry.err = value, err
+	now := dc.now()
+	switch {
+	case err == nil:
+		entry.fresh = now.Add(dc.jitter(dc.TTL))
+		entry.stale = entry.fresh.Add(dc.jitter(dc.StaleTTL))
+	case dc.NegativeTTL > 0 && !isContextError(err):
+		entry.fresh = now.Add(dc.jitter(dc.NegativeTTL))
+		entry.stale = entry.fresh
+	default:
+		entry.fresh, entry.stale = time.Time{}, time.Time{}
+		if dc.entries[key] == entry {
+			delete(dc.entries, key)
+		}
+	}
+}
+
+// add stores the entry of the key, creating the map of entries of the zero value. The mutex is held.
+func (dc *DataCache) add(key interface{}, entry *dataEntry) {
+	if dc.entries == nil {
+		dc.entries = make(map[interface{}]*dataEntry)
+	}
+	dc.entries[key] = entry
+}
+
+func (dc *DataCache) now() time.Time {
+	if dc.Clock == nil {
+		return SystemClock.Now()
+	}
+	return dc.Clock.Now()
+}
+
+// isContextError reports whether the error is of a cancelled context or of one past its deadline.
+func isContextError(err error) bool {
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
+
+func (dc *DataCache) jitter(d time.Duration) time.Duration {
+	if dc.Jitter <= 0 {
+		return d
+	}
+	return d - time.Duration(rand.Float64()*dc.Jitter*float64(d))
+}
+
+// wait waits for the first fetch of the entry.
+func (dc *DataCache) wait(ctx context.Context, entry *dataEntry) (interface{}, error) {
+	select {
+	case <-entry.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+	return entry.value, entry.err
+}
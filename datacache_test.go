@@ -0,0 +1,186 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// versions returns a fetch function returning the number of fetches so far.
+func versions(fetches *int32) FetchFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		return int(atomic.AddInt32(fetches, 1)), nil
+	}
+}
+
+func TestDataCacheStaleWhileRevalidate(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewDataCache(time.Minute, time.Hour)
+	cache.Clock = clock
+	var fetches int32
+	get := func() interface{} {
+		value, err := cache.Get(context.Background(), "author:1", versions(&fetches))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return value
+	}
+
+	if value := get(); value != 1 {
+		t.Fatalf("expected the first version, got %v", value)
+	}
+	clock.Advance(30 * time.Second)
+	if value := get(); value != 1 || atomic.LoadInt32(&fetches) != 1 {
+		t.Errorf("fresh data should not be fetched again, got %v", value)
+	}
+
+	clock.Advance(time.Minute)
+	if value := get(); value != 1 {
+		t.Errorf("stale data should be returned right away, got %v", value)
+	}
+	for i := 0; get() != 2 && i < 100; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if value, n := get(), atomic.LoadInt32(&fetches); value != 2 || n != 2 {
+		t.Errorf("stale data should be refreshed in the background once, got %v after %d fetches", value, n)
+	}
+
+	clock.Advance(2 * time.Hour)
+	if value := get(); value != 3 {
+		t.Errorf("data older than the stale TTL should be fetched before it is returned, got %v", value)
+	}
+}
+
+func TestDataCacheNegative(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewDataCache(time.Minute, time.Hour)
+	cache.Clock = clock
+	notFound := errors.New("not found")
+	var fetches int32
+	fetch := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&fetches, 1)
+		return nil, notFound
+	}
+	for _, test := range []struct {
+		negativeTTL time.Duration
+		fetches     int32
+	}{
+		{0, 2},
+		{time.Second, 1},
+	} {
+		cache.NegativeTTL = test.negativeTTL
+		fetches = 0
+		for i := 0; i < 2; i++ {
+			if _, err := cache.Get(context.Background(), test.negativeTTL, fetch); err != notFound {
+				t.Errorf("expected error %v, got %v", notFound, err)
+			}
+		}
+		if fetches != test.fetches {
+			t.Errorf("negative TTL %v: expected %d fetches, got %d", test.negativeTTL, test.fetches, fetches)
+		}
+	}
+	clock.Advance(time.Second)
+	cache.Get(context.Background(), time.Second, fetch)
+	if fetches != 2 {
+		t.Error("expired errors should be fetched again")
+	}
+}
+
+func TestDataCacheCoalesces(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewDataCache(time.Minute, 0)
+	var fetches int32
+	slow := func(ctx context.Context) (interface{}, error) {
+		<-clock.After(10 * time.Millisecond)
+		return versions(&fetches)(ctx)
+	}
+	results := make(chan interface{}, 3)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			value, _ := cache.Get(context.Background(), "key", slow)
+			results <- value
+		}()
+	}
+	for clock.Timers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, ok, _ := cache.Lookup("key", slow); ok {
+		t.Error("Lookup should not wait for a fetch in flight")
+	}
+	clock.Advance(10 * time.Millisecond)
+	for i := 0; i < cap(results); i++ {
+		if value := <-results; value != 1 {
+			t.Errorf("expected the first version, got %v", value)
+		}
+	}
+	if fetches != 1 {
+		t.Errorf("concurrent fetches should be coalesced, got %d fetches", fetches)
+	}
+}
+
+func TestDataCacheJitter(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	cache := NewDataCache(time.Minute, 0)
+	cache.Clock = clock
+	cache.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		cache.Set(i, i, nil)
+	}
+	clock.Advance(30 * time.Second)
+	for i := 0; i < 100; i++ {
+		if _, ok, _ := cache.Lookup(i, nil); !ok {
+			t.Fatalf("entry %d expired before TTL*(1-Jitter)", i)
+		}
+	}
+	clock.Advance(30 * time.Second)
+	for i := 0; i < 100; i++ {
+		if _, ok, _ := cache.Lookup(i, nil); ok {
+			t.Fatalf("entry %d outlived TTL", i)
+		}
+	}
+}
+
+func TestDataCacheFetcherCancelled(t *testing.T) {
+	cache := NewDataCache(time.Minute, 0)
+	var fetches int32
+	started, cancelled := make(chan struct{}), make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	go cache.Get(ctx, "key", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-ctx.Done()
+		defer close(cancelled)
+		return nil, ctx.Err()
+	})
+	<-started
+	result := make(chan interface{})
+	go func() {
+		value, err := cache.Get(context.Background(), "key", versions(&fetches))
+		if err != nil {
+			t.Errorf("the waiter should not get the error of the cancelled fetcher, got: %v", err)
+		}
+		result <- value
+	}()
+	time.Sleep(10 * time.Millisecond) // lets the second caller wait for the fetch in flight
+	cancel()
+	<-cancelled
+	if value := <-result; value != 1 {
+		t.Errorf("the waiter should fetch the data itself, got %v", value)
+	}
+}
+
+func TestDataCacheZeroValue(t *testing.T) {
+	var cache DataCache
+	var fetches int32
+	for i := 0; i < 2; i++ {
+		if value, err := cache.Get(context.Background(), "key", versions(&fetches)); err != nil || value != i+1 {
+			t.Errorf("data should not be cached without TTL, got %v, %v", value, err)
+		}
+	}
+	cache.TTL = time.Minute
+	cache.Set("key", 0, nil)
+	if value, ok, _ := cache.Lookup("key", versions(&fetches)); !ok || value != 0 {
+		t.Errorf("expected the data set, got %v, %v", value, ok)
+	}
+}
@@ -0,0 +1,27 @@
+package templates
+
+import (
+	"github.com/strongo/templates/inspiration/builtin/html"
+)
+
+// Escapers generated code passes printed values through. The compiler picks them for the context
+// of the value in the static text around it, e.g. EscapeJSVal in <script> or NormalizeURL in href="...",
+// see html.Context. Values of safe content types, e.g. HTML, are passed through where the context matches,
+// values of other types are formatted as fmt.Sprint does.
+var (
+	EscapeHTML         = html.Escapers["EscapeHTML"]         // text between tags
+	EscapeRCDATA       = html.Escapers["EscapeRCDATA"]       // text of <title> and <textarea>
+	EscapeAttr         = html.Escapers["EscapeAttr"]         // quoted attribute values
+	EscapeUnquotedAttr = html.Escapers["EscapeUnquotedAttr"] // unquoted attribute values
+	EscapeComment      = html.Escapers["EscapeComment"]      // HTML, JS and CSS comments, printed as nothing
+	FilterHTMLName     = html.Escapers["FilterHTMLName"]     // attribute names
+	EscapeJSVal        = html.Escapers["EscapeJSVal"]        // JS expressions, as JSON values
+	EscapeJSStr        = html.Escapers["EscapeJSStr"]        // JS string literals
+	EscapeJSRegexp     = html.Escapers["EscapeJSRegexp"]     // JS regular expression literals
+	EscapeCSS          = html.Escapers["EscapeCSS"]          // CSS strings
+	FilterCSSValue     = html.Escapers["FilterCSSValue"]     // CSS values
+	FilterURL          = html.Escapers["FilterURL"]          // URLs of unsafe schemes, e.g. javascript:, are replaced
+	NormalizeURL       = html.Escapers["NormalizeURL"]       // URLs and their paths
+	EscapeURL          = html.Escapers["EscapeURL"]          // query and fragment parts of URLs
+	EscapeSrcset       = html.Escapers["EscapeSrcset"]       // srcset attribute values
+)
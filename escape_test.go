@@ -0,0 +1,32 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestEscapers(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		escaper  func(...interface{}) string
+		value    interface{}
+		expected string
+	}{
+		{"EscapeHTML", EscapeHTML, `<b>"Hi"</b>`, "&lt;b&gt;&#34;Hi&#34;&lt;/b&gt;"},
+		{"EscapeHTML", EscapeHTML, HTML(`<b>"Hi"</b>`), `<b>"Hi"</b>`},
+		{"EscapeHTML", EscapeHTML, 5, "5"},
+		{"EscapeAttr", EscapeAttr, HTML(`<b>"Hi"</b>`), "&#34;Hi&#34;"},
+		{"EscapeJSVal", EscapeJSVal, `</script>`, `"\u003c/script\u003e"`},
+		{"EscapeJSVal", EscapeJSVal, JS(`f(1)`), `f(1)`},
+		{"EscapeJSVal", EscapeJSVal, URL(`/a`), `"/a"`},
+		{"FilterCSSValue", FilterCSSValue, CSS(`color: red`), `color: red`},
+		{"FilterCSSValue", FilterCSSValue, HTML(`<b>Hi</b>`), "ZgotmplZ"},
+		{"FilterURL", FilterURL, `javascript:alert(1)`, "#ZgotmplZ"},
+		{"FilterURL", FilterURL, URL(`javascript:alert(1)`), `javascript:alert(1)`},
+		{"EscapeSrcset", EscapeSrcset, `a.png 1x, javascript:alert(1) 2x`, "a.png 1x,#ZgotmplZ"},
+		{"EscapeSrcset", EscapeSrcset, Srcset(`a.png 1x, b.png 2x`), `a.png 1x, b.png 2x`},
+	} {
+		if s := test.escaper(test.value); s != test.expected {
+			t.Errorf("%s(%#v): expected %q, got %q", test.name, test.value, test.expected, s)
+		}
+	}
+}
@@ -0,0 +1,302 @@
+package templates
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FragmentKey identifies rendered HTML of a block or a component.
+type FragmentKey struct {
+	Template string // path of the template or name of the component
+	Block    string // name of the block; empty for a component
+	Payload  uint64 // hash of the payload, see PayloadHash
+	Locale   string
+}
+
+func (k FragmentKey) String() string {
+	return k.Template + "#" + k.Block + "@" + k.Locale + ":" + strconv.FormatUint(k.Payload, 16)
+}
+
+// PayloadHash hashes the payload for a fragment key. Pointers are followed, so payloads
+// pointing to equal values hash the same; functions and channels are hashed by address.
+func PayloadHash(payload interface{}) uint64 {
+	h := fnv.New64a()
+	writeValue(h, reflect.ValueOf(payload), make(map[uintptr]bool))
+	return h.Sum64()
+}
+
+// writeValue writes the value much like %#v formats it, but with values pointers point to in place of addresses.
+// Pointers in visited are being written already and are written as <cycle>.
+func writeValue(w io.Writer, v reflect.Value, visited map[uintptr]bool) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		io.WriteString(w, "nil")
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprintf(w, "(%s)(nil)", v.Type())
+			return
+		}
+		if visited[v.Pointer()] {
+			io.WriteString(w, "<cycle>")
+			return
+		}
+		visited[v.Pointer()] = true
+		defer delete(visited, v.Pointer())
+		io.WriteString(w, "&")
+		writeValue(w, v.Elem(), visited)
+	case reflect.Interface:
+		if v.IsNil() {
+			io.WriteString(w, "nil")
+			return
+		}
+		writeValue(w, v.Elem(), visited)
+	case reflect.Struct:
+		fmt.Fprintf(w, "%s{", v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(w, "%s:", v.Type().Field(i).Name)
+			writeValue(w, v.Field(i), visited)
+			io.WriteString(w, ", ")
+		}
+		io.WriteString(w, "}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			fmt.Fprintf(w, "%s(nil)", v.Type())
+			return
+		}
+		fmt.Fprintf(w, "%s{", v.Type())
+		for i := 0; i < v.Len(); i++ {
+			writeValue(w, v.Index(i), visited)
+			io.WriteString(w, ", ")
+		}
+		io.WriteString(w, "}")
+	case reflect.Map:
+		if v.IsNil() {
+			fmt.Fprintf(w, "%s(nil)", v.Type())
+			return
+		}
+		entries := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			entry := new(bytes.Buffer)
+			writeValue(entry, key, visited)
+			entry.WriteString(":")
+			writeValue(entry, v.MapIndex(key), visited)
+			entries = append(entries, entry.String())
+		}
+		sort.Strings(entries)
+		fmt.Fprintf(w, "%s{%s}", v.Type(), strings.Join(entries, ", "))
+	default:
+		fmt.Fprintf(w, "%s(%#v)", v.Type(), v)
+	}
+}
+
+// FragmentStorage stores rendered fragments, e.g. in memory or in memcache.
+// It must be safe for concurrent use.
+type FragmentStorage interface {
+	Get(key string) (fragment []byte, ok bool)
+	Set(key string, fragment []byte, ttl time.Duration)
+}
+
+// FragmentCache caches HTML of blocks and components rendering the same for the same payload and locale.
+// A nil cache caches nothing.
+type FragmentCache struct {
+	Storage FragmentStorage
+	TTL     time.Duration // how long fragments are cached if the block or component does not set it
+}
+
+// Fragments is the cache of blocks marked with @cache in templates.
+var Fragments = NewFragmentCache(NewLRUStorage(32<<20), 10*time.Minute)
+
+func NewFragmentCache(storage FragmentStorage, ttl time.Duration) *FragmentCache {
+	return &FragmentCache{Storage: storage, TTL: ttl}
+}
+
+// Render writes the cached fragment of the key, or renders it with the render function and caches it.
+// A fragment is not cached if a component in it failed to load and rendered its fallback or nothing.
+// Components in the fragment are rendered in order even if the render context renders out of order.
+func (fc *FragmentCache) Render(c RenderContext, key FragmentKey, ttl time.Duration, render RenderFunc) error {
+	if fc == nil {
+		return render(c)
+	}
+	k := key.String()
+	if cached, ok := fc.Storage.Get(k); ok {
+		return writeFragment(c, cached)
+	}
+	return fc.render(c, k, ttl, render)
+}
+
+// render renders the fragment into a buffer, caches it unless it is spoiled and writes it to the render context.
+func (fc *FragmentCache) render(c RenderContext, key string, ttl time.Duration, render RenderFunc) error {
+	output := bufferPool.Get().(*bytes.Buffer)
+	defer func() {
+		output.Reset()
+		bufferPool.Put(output)
+	}()
+	f := &fragment{parent: c.fragment}
+	rc := c
+	rc.Writer = output
+	rc.pipe = nil
+	rc.fragment = f
+	if err := render(rc); err != nil {
+		return err
+	}
+	if !f.isSpoiled() {
+		if ttl == 0 {
+			ttl = fc.TTL
+		}
+		fc.Storage.Set(key, append([]byte(nil), output.Bytes()...), ttl)
+	}
+	_, err := c.Writer.Write(output.Bytes())
+	return err
+}
+
+// writeFragment writes a cached fragment unless the render is cancelled.
+func writeFragment(c RenderContext, cached []byte) error {
+	if err := c.Ctx().Err(); err != nil {
+		return err
+	}
+	_, err := c.Writer.Write(cached)
+	return err
+}
+
+// Component is a part of a page getting its data and rendering it, e.g. prototype.AuthorCard.
+type Component interface {
+	IStrongoComponent
+	Render(c RenderContext) error
+}
+
+// Component returns the component caching its HTML under the key.
+// On a cache hit GetData and Render of the component are skipped and the cached HTML is written instead.
+func (fc *FragmentCache) Component(key FragmentKey, ttl time.Duration, component Component) Component {
+	if fc == nil {
+		return component
+	}
+	return &cachedComponent{cache: fc, key: key.String(), ttl: ttl, component: component}
+}
+
+type cachedComponent struct {
+	cache     *FragmentCache
+	key       string
+	ttl       time.Duration
+	component Component
+	hit       bool   // whether GetData found the HTML in the cache
+	cached    []byte // HTML found by GetData
+}
+
+func (cc *cachedComponent) GetData(ctx context.Context) error {
+	if cc.cached, cc.hit = cc.cache.Storage.Get(cc.key); cc.hit {
+		return nil
+	}
+	return cc.component.GetData(ctx)
+}
+
+func (cc *cachedComponent) Render(c RenderContext) error {
+	if cc.hit {
+		return writeFragment(c, cc.cached)
+	}
+	return cc.cache.render(c, cc.key, cc.ttl, cc.component.Render)
+}
+
+// fragment is a fragment being rendered for the cache.
+type fragment struct {
+	parent  *fragment // fragment the fragment is nested in
+	mutex   sync.Mutex
+	spoiled bool
+}
+
+// spoil marks the fragment and fragments it is nested in as not to be cached.
+func (f *fragment) spoil() {
+	for ; f != nil; f = f.parent {
+		f.mutex.Lock()
+		f.spoiled = true
+		f.mutex.Unlock()
+	}
+}
+
+func (f *fragment) isSpoiled() bool {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.spoiled
+}
+
+// LRUStorage is an in-memory fragment storage evicting least recently used fragments
+// once their total size exceeds the limit.
+type LRUStorage struct {
+	maxBytes int
+	clock    Clock
+
+	mutex   sync.Mutex
+	bytes   int
+	order   *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key      string
+	fragment []byte
+	expires  time.Time // never if zero
+}
+
+// NewLRUStorage creates a storage holding up to maxBytes of fragments.
+func NewLRUStorage(maxBytes int) *LRUStorage {
+	return &LRUStorage{
+		maxBytes: maxBytes,
+		clock:    SystemClock,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (s *LRUStorage) Get(key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !s.clock.Now().Before(entry.expires) {
+		s.remove(element)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return entry.fragment, true
+}
+
+// Set stores the fragment for the ttl, or until it is evicted; a ttl of 0 means no expiration.
+// Fragments larger than the limit are not stored.
+func (s *LRUStorage) Set(key string, fragment []byte, ttl time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if element, ok := s.entries[key]; ok {
+		s.remove(element)
+	}
+	if len(fragment) > s.maxBytes {
+		return
+	}
+	entry := &lruEntry{key: key, fragment: fragment}
+	if ttl > 0 {
+		entry.expires = s.clock.Now().Add(ttl)
+	}
+	s.entries[key] = s.order.PushFront(entry)
+	s.bytes += len(fragment)
+	for s.bytes > s.maxBytes {
+		s.remove(s.order.Back())
+	}
+}
+
+// remove removes the entry of the element. The mutex is held.
+func (s *LRUStorage) remove(element *list.Element) {
+	entry := s.order.Remove(element).(*lruEntry)
+	delete(s.entries, entry.key)
+	s.bytes -= len(entry.fragment)
+}
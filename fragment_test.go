@@ -0,0 +1,156 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFragmentCacheRender(t *testing.T) {
+	cache := NewFragmentCache(NewLRUStorage(1024), time.Minute)
+	key := FragmentKey{Template: "index.html", Block: "menu", Payload: PayloadHash(struct{ ID int }{1}), Locale: "ru_RU"}
+	var renders int
+	render := func(c RenderContext) error {
+		renders++
+		_, err := c.WriteString("<ul></ul>")
+		return err
+	}
+	for i := 0; i < 2; i++ {
+		writer := new(bytes.Buffer)
+		if err := cache.Render(RenderContext{Writer: writer}, key, 0, render); err != nil {
+			t.Fatal(err)
+		}
+		if writer.String() != "<ul></ul>" {
+			t.Errorf("unexpected output: %q", writer.String())
+		}
+	}
+	if renders != 1 {
+		t.Errorf("cached fragment should be rendered once, got %d renders", renders)
+	}
+	key.Locale = "en_US"
+	cache.Render(RenderContext{Writer: new(bytes.Buffer)}, key, 0, render)
+	if renders != 2 {
+		t.Error("fragments of other locales should be rendered")
+	}
+
+	failed := errors.New("failed")
+	key.Block = "failing"
+	if err := cache.Render(RenderContext{Writer: new(bytes.Buffer)}, key, 0, func(c RenderContext) error {
+		return failed
+	}); err != failed {
+		t.Errorf("expected error %v, got %v", failed, err)
+	}
+	if _, ok := cache.Storage.Get(key.String()); ok {
+		t.Error("failed fragments should not be cached")
+	}
+
+	var nilCache *FragmentCache
+	nilCache.Render(RenderContext{Writer: new(bytes.Buffer)}, key, 0, render)
+	if renders != 3 {
+		t.Error("nil cache should render every time")
+	}
+}
+
+// cachedCard is a component counting its GetData calls.
+type cachedCard struct {
+	component *StrongoComponent
+	getData   int
+	err       error
+}
+
+func (card *cachedCard) GetData(ctx context.Context) error {
+	card.getData++
+	card.component.OnDataReady(card.err)
+	return nil
+}
+
+func (card *cachedCard) Render(c RenderContext) error {
+	return card.component.RenderWhenReady(c, func(c RenderContext) error {
+		_, err := c.WriteString("card")
+		return err
+	})
+}
+
+func TestPayloadHash(t *testing.T) {
+	type node struct {
+		Name     string
+		Tags     map[string][]int
+		Next     *node
+		Value    interface{}
+		internal *string
+	}
+	internal := "x"
+	payload := func(name string) node {
+		n := &node{Name: name, Tags: map[string][]int{"a": {1}, "b": nil}, Value: &internal, internal: &internal}
+		n.Next = n
+		return node{Name: "root", Next: n}
+	}
+	if PayloadHash(payload("a")) != PayloadHash(payload("a")) {
+		t.Error("payloads pointing to equal values should hash the same")
+	}
+	if PayloadHash(payload("a")) == PayloadHash(payload("b")) {
+		t.Error("payloads pointing to different values should hash differently")
+	}
+	if PayloadHash(int32(1)) == PayloadHash(int64(1)) {
+		t.Error("values of different types should hash differently")
+	}
+}
+
+func TestFragmentCacheComponent(t *testing.T) {
+	cache := NewFragmentCache(NewLRUStorage(1024), time.Minute)
+	key := FragmentKey{Template: "Card", Payload: 1}
+	render := func(card *cachedCard) string {
+		card.component = NewStrongoComponent(nil)
+		card.component.SetErrorPolicy(FallbackOnError, func(c RenderContext, err error) error {
+			_, err = c.WriteString("fallback")
+			return err
+		})
+		component := cache.Component(key, 0, card)
+		component.GetData(context.Background())
+		writer := new(bytes.Buffer)
+		if err := component.Render(RenderContext{Writer: writer}.WithOutOfOrder()); err != nil {
+			t.Fatal(err)
+		}
+		return writer.String()
+	}
+
+	failing := &cachedCard{err: errors.New("failed")}
+	if s := render(failing); s != "fallback" {
+		t.Errorf("unexpected output: %q", s)
+	}
+	card := new(cachedCard)
+	if s := render(card); s != "card" || card.getData != 1 {
+		t.Errorf("fallback should not be cached, got %q", s)
+	}
+	card = new(cachedCard)
+	if s := render(card); s != "card" || card.getData != 0 {
+		t.Errorf("GetData should be skipped on a cache hit, got %q after %d GetData calls", s, card.getData)
+	}
+}
+
+func TestLRUStorage(t *testing.T) {
+	clock := NewFakeClock(time.Now())
+	storage := NewLRUStorage(6)
+	storage.clock = clock
+	storage.Set("a", []byte("aa"), 0)
+	storage.Set("b", []byte("bb"), time.Minute)
+	storage.Set("c", []byte("cc"), 0)
+	storage.Get("a")
+	storage.Set("d", []byte("dd"), 0) // evicts b, the least recently used
+	for key, cached := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, ok := storage.Get(key); ok != cached {
+			t.Errorf("%s: expected cached %v", key, cached)
+		}
+	}
+	storage.Set("e", []byte("e"), time.Minute)
+	clock.Advance(time.Minute)
+	if _, ok := storage.Get("e"); ok {
+		t.Error("expired fragment should not be returned")
+	}
+	storage.Set("f", []byte("too large"), 0)
+	if _, ok := storage.Get("f"); ok || storage.bytes > 6 {
+		t.Errorf("fragment larger than the limit should not be stored, %d bytes stored", storage.bytes)
+	}
+}
@@ -0,0 +1,755 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContextSeparator separates the context of a message from its source string in catalog keys,
+// as in gettext .mo files, e.g. "menu\x04Open".
+const ContextSeparator = "\x04"
+
+// ContextKey returns the catalog key of the source string in the context.
+func ContextKey(context, key string) string {
+	if context == "" {
+		return key
+	}
+	return context + ContextSeparator + key
+}
+
+// PluralCatalog is implemented by catalogs declaring their own plural rule,
+// e.g. by the Plural-Forms header of a gettext catalog.
+type PluralCatalog interface {
+	Catalog
+	// PluralForm returns the index of the plural form for n; ok is false if the catalog has no rule.
+	PluralForm(n int) (form int, ok bool)
+}
+
+// GettextCatalog is a catalog read from a gettext .po or .mo file.
+type GettextCatalog struct {
+	Messages Messages
+	Header   map[string]string // fields of the header entry, e.g. "Language" or "Plural-Forms"
+	nplurals int
+	plural   PluralRule
+}
+
+func (c *GettextCatalog) Translation(key string) (string, bool) {
+	return c.Messages.Translation(key)
+}
+
+func (c *GettextCatalog) PluralForm(n int) (int, bool) {
+	if c.plural == nil {
+		return 0, false
+	}
+	if n < 0 {
+		n = -n
+	}
+	form := c.plural(n)
+	if form < 0 || form >= c.nplurals {
+		return 0, false
+	}
+	return form, true
+}
+
+// setHeader parses the header entry, the translation of the empty source string.
+func (c *GettextCatalog) setHeader(header string) error {
+	c.Header = make(map[string]string)
+	for _, line := range strings.Split(header, "\n") {
+		if i := strings.IndexByte(line, ':'); i > 0 {
+			c.Header[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if pluralForms := c.Header["Plural-Forms"]; pluralForms != "" {
+		nplurals, plural, err := ParsePluralForms(pluralForms)
+		if err != nil {
+			return err
+		}
+		c.nplurals, c.plural = nplurals, plural
+	}
+	return nil
+}
+
+// ParsePluralForms parses the Plural-Forms header of a gettext catalog,
+// e.g. "nplurals=2; plural=(n != 1);", into the number of forms and the rule.
+func ParsePluralForms(header string) (nplurals int, rule PluralRule, err error) {
+	for _, field := range strings.Split(header, ";") {
+		field = strings.TrimSpace(field)
+		switch {
+		case strings.HasPrefix(field, "nplurals="):
+			if nplurals, err = strconv.Atoi(strings.TrimSpace(field[len("nplurals="):])); err != nil || nplurals < 1 {
+				return 0, nil, fmt.Errorf("invalid Plural-Forms %q: bad nplurals", header)
+			}
+		case strings.HasPrefix(field, "plural="):
+			if rule, err = parsePluralExpr(field[len("plural="):]); err != nil {
+				return 0, nil, fmt.Errorf("invalid Plural-Forms %q: %v", header, err)
+			}
+		}
+	}
+	if nplurals == 0 || rule == nil {
+		return 0, nil, fmt.Errorf("invalid Plural-Forms %q: nplurals and plural are required", header)
+	}
+	return nplurals, rule, nil
+}
+
+// ReadPO reads a gettext .po file. Fuzzy and obsolete entries are left out, as msgfmt does,
+// and so are entries without a translation.
+func ReadPO(r io.Reader) (*GettextCatalog, error) {
+	po, err := ParsePO(r)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &GettextCatalog{Messages: make(Messages)}
+	for _, entry := range po.Entries {
+		switch {
+		case entry.Obsolete:
+		case entry.ID == "" && entry.Context == "":
+			if err = catalog.setHeader(entry.Translation()); err != nil {
+				return nil, err
+			}
+		case !entry.Fuzzy() && entry.Translated():
+			catalog.Messages[entry.Key()] = strings.Join(entry.Translations, "\x00")
+		}
+	}
+	return catalog, nil
+}
+
+// POFile is a gettext .po file: translations to one locale, or a .pot template of them.
+// The header is the entry with the empty source string, usually the first one.
+type POFile struct {
+	Entries []*POEntry
+}
+
+// POEntry is a message of a .po file with its comments.
+type POEntry struct {
+	Comments          []string // translator comments, "# ..."
+	ExtractedComments []string // comments for translators found in sources, "#. ..."
+	References        []string // source locations, "#: index.html:12"
+	Flags             []string // e.g. fuzzy or c-format, "#, ..."
+
+	Context      string   // msgctxt
+	ID           string   // msgid, the source string
+	Plural       string   // msgid_plural, the plural source string; empty if the message has no plural forms
+	Translations []string // msgstr, or msgstr[n] of plural forms
+	Obsolete     bool     // the message is no longer in sources, "#~ msgid ..."
+}
+
+// Key returns the key of the message in catalogs, see ContextKey.
+func (e *POEntry) Key() string {
+	return ContextKey(e.Context, e.ID)
+}
+
+// Translation returns msgstr, or msgstr[0] of plural forms.
+func (e *POEntry) Translation() string {
+	if len(e.Translations) == 0 {
+		return ""
+	}
+	return e.Translations[0]
+}
+
+// Translated reports whether any form of the message is translated.
+func (e *POEntry) Translated() bool {
+	for _, translation := range e.Translations {
+		if translation != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *POEntry) Fuzzy() bool {
+	for _, flag := range e.Flags {
+		if flag == "fuzzy" {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePO parses a gettext .po or .pot file.
+func ParsePO(r io.Reader) (*POFile, error) {
+	po := new(POFile)
+	var (
+		entry            *POEntry
+		hasID, hasMsgstr bool    // of the entry
+		last             *string // the string continued by following quoted lines
+		lineNo           int
+		scanner          = bufio.NewScanner(r)
+		keywords         = []string{"msgctxt", "msgid_plural", "msgid", "msgstr"}
+	)
+	// current returns the entry of comments and source strings, starting a new one after msgstr.
+	current := func() *POEntry {
+		if entry == nil || hasMsgstr {
+			entry, hasID, hasMsgstr, last = new(POEntry), false, false, nil
+			po.Entries = append(po.Entries, entry)
+		}
+		return entry
+	}
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		obsolete := strings.HasPrefix(line, "#~")
+		if obsolete {
+			if line = strings.TrimSpace(line[2:]); strings.HasPrefix(line, "|") {
+				continue // the previous source string of an obsolete entry
+			}
+		}
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			e := current()
+			kind, text := line, ""
+			if len(line) > 1 {
+				kind, text = line[:2], strings.TrimSpace(line[2:])
+			}
+			switch kind {
+			case "#:":
+				e.References = append(e.References, strings.Fields(text)...)
+			case "#,":
+				for _, flag := range strings.Split(text, ",") {
+					if flag = strings.TrimSpace(flag); flag != "" {
+						e.Flags = append(e.Flags, flag)
+					}
+				}
+			case "#.":
+				e.ExtractedComments = append(e.ExtractedComments, text)
+			case "#|":
+				// the previous source string of a fuzzy entry
+			default:
+				e.Comments = append(e.Comments, strings.TrimPrefix(line[1:], " "))
+			}
+			continue
+		case strings.HasPrefix(line, `"`):
+			if last == nil {
+				return nil, fmt.Errorf("po:%d: unexpected string", lineNo)
+			}
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return nil, fmt.Errorf("po:%d: invalid string %s", lineNo, line)
+			}
+			*last += s
+			continue
+		}
+		keyword := ""
+		for _, k := range keywords {
+			if strings.HasPrefix(line, k) {
+				keyword = k
+				break
+			}
+		}
+		if keyword == "" {
+			return nil, fmt.Errorf("po:%d: unexpected %q", lineNo, line)
+		}
+		rest := strings.TrimSpace(line[len(keyword):])
+		index := 0
+		if keyword == "msgstr" && strings.HasPrefix(rest, "[") {
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("po:%d: invalid msgstr index", lineNo)
+			}
+			var err error
+			if index, err = strconv.Atoi(rest[1:end]); err != nil || index < 0 {
+				return nil, fmt.Errorf("po:%d: invalid msgstr index", lineNo)
+			}
+			rest = strings.TrimSpace(rest[end+1:])
+		}
+		s, err := strconv.Unquote(rest)
+		if err != nil {
+			return nil, fmt.Errorf("po:%d: invalid string %s", lineNo, rest)
+		}
+		switch keyword {
+		case "msgctxt", "msgid":
+			e := current()
+			if hasID {
+				return nil, fmt.Errorf("po:%d: msgid %q without msgstr", lineNo, e.ID)
+			}
+			if keyword == "msgctxt" {
+				e.Context = s
+				last = &e.Context
+			} else {
+				e.ID, hasID = s, true
+				last = &e.ID
+			}
+		case "msgid_plural":
+			if !hasID || hasMsgstr {
+				return nil, fmt.Errorf("po:%d: msgid_plural without msgid", lineNo)
+			}
+			entry.Plural = s
+			last = &entry.Plural
+		case "msgstr":
+			if !hasID {
+				return nil, fmt.Errorf("po:%d: msgstr without msgid", lineNo)
+			}
+			hasMsgstr = true
+			for len(entry.Translations) <= index {
+				entry.Translations = append(entry.Translations, "")
+			}
+			entry.Translations[index] = s
+			last = &entry.Translations[index]
+		}
+		entry.Obsolete = entry.Obsolete || obsolete
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if entry != nil && !hasMsgstr {
+		if hasID {
+			return nil, fmt.Errorf("po:%d: msgid %q without msgstr", lineNo, entry.ID)
+		}
+		po.Entries = po.Entries[:len(po.Entries)-1] // trailing comments
+	}
+	return po, nil
+}
+
+// Header returns the header entry, or nil.
+func (f *POFile) Header() *POEntry {
+	for _, entry := range f.Entries {
+		if entry.ID == "" && entry.Context == "" && !entry.Obsolete {
+			return entry
+		}
+	}
+	return nil
+}
+
+// HeaderField returns the field of the header, e.g. "Plural-Forms", or an empty string.
+func (f *POFile) HeaderField(name string) string {
+	if header := f.Header(); header != nil {
+		for _, line := range strings.Split(header.Translation(), "\n") {
+			if i := strings.IndexByte(line, ':'); i > 0 && strings.TrimSpace(line[:i]) == name {
+				return strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	return ""
+}
+
+// Write writes the file in the format of gettext tools, entries separated by empty lines.
+func (f *POFile) Write(w io.Writer) error {
+	var b bytes.Buffer
+	for i, entry := range f.Entries {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		for _, comment := range entry.Comments {
+			b.WriteString(strings.TrimRight("# "+comment, " ") + "\n")
+		}
+		for _, comment := range entry.ExtractedComments {
+			b.WriteString("#. " + comment + "\n")
+		}
+		if len(entry.References) > 0 {
+			b.WriteString("#: " + strings.Join(entry.References, " ") + "\n")
+		}
+		if len(entry.Flags) > 0 {
+			b.WriteString("#, " + strings.Join(entry.Flags, ", ") + "\n")
+		}
+		prefix := ""
+		if entry.Obsolete {
+			prefix = "#~ "
+		}
+		field := func(keyword, s string) {
+			b.WriteString(prefix + keyword + " " + strings.Replace(poString(s), "\n", "\n"+prefix, -1) + "\n")
+		}
+		if entry.Context != "" {
+			field("msgctxt", entry.Context)
+		}
+		field("msgid", entry.ID)
+		if entry.Plural != "" {
+			field("msgid_plural", entry.Plural)
+			for i, translation := range entry.Translations {
+				field(fmt.Sprintf("msgstr[%d]", i), translation)
+			}
+		} else {
+			field("msgstr", entry.Translation())
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// poString quotes the string for a .po file, splitting it after newlines into continued lines.
+func poString(s string) string {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") {
+		return poQuote(s)
+	}
+	var b bytes.Buffer
+	b.WriteString(`""`)
+	for _, line := range strings.SplitAfter(s, "\n") {
+		if line != "" {
+			b.WriteString("\n" + poQuote(line))
+		}
+	}
+	return b.String()
+}
+
+// poQuote quotes the string with C escapes understood by gettext tools.
+func poQuote(s string) string {
+	var b bytes.Buffer
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ReadMO reads a compiled gettext .mo file of either byte order.
+func ReadMO(r io.Reader) (*GettextCatalog, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 20 {
+		return nil, fmt.Errorf("mo: file too short")
+	}
+	var order binary.ByteOrder
+	switch binary.LittleEndian.Uint32(data) {
+	case 0x950412de:
+		order = binary.LittleEndian
+	case 0xde120495:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("mo: bad magic number")
+	}
+	if revision := order.Uint32(data[4:]) >> 16; revision > 1 {
+		return nil, fmt.Errorf("mo: unsupported revision %d", revision)
+	}
+	count, originals, translations := order.Uint32(data[8:]), order.Uint32(data[12:]), order.Uint32(data[16:])
+	str := func(table uint32, i uint32) (string, error) {
+		at := uint64(table) + 8*uint64(i)
+		if at+8 > uint64(len(data)) {
+			return "", fmt.Errorf("mo: string table out of range")
+		}
+		length, offset := uint64(order.Uint32(data[at:])), uint64(order.Uint32(data[at+4:]))
+		if offset+length > uint64(len(data)) {
+			return "", fmt.Errorf("mo: string out of range")
+		}
+		return string(data[offset : offset+length]), nil
+	}
+	catalog := &GettextCatalog{Messages: make(Messages)}
+	for i := uint32(0); i < count; i++ {
+		original, err := str(originals, i)
+		if err != nil {
+			return nil, err
+		}
+		translation, err := str(translations, i)
+		if err != nil {
+			return nil, err
+		}
+		if original == "" {
+			if err = catalog.setHeader(translation); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if i := strings.IndexByte(original, 0); i >= 0 {
+			original = original[:i] // msgid_plural follows msgid
+		}
+		catalog.Messages[original] = translation
+	}
+	return catalog, nil
+}
+
+// LoadGettext reads a .po or .mo file, by its extension.
+func LoadGettext(path string) (*GettextCatalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var catalog *GettextCatalog
+	switch ext := filepath.Ext(path); ext {
+	case ".po":
+		catalog, err = ReadPO(f)
+	case ".mo":
+		catalog, err = ReadMO(f)
+	default:
+		return nil, fmt.Errorf("%s: not a gettext catalog", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return catalog, nil
+}
+
+// GettextFile is a gettext catalog file reloaded once it changes, if Reload is set,
+// e.g. in development while translators edit it. The file is checked for changes
+// at most once per Interval, on lookups of translations. Without Reload lookups take no lock,
+// so Reload should not change once the file is in use.
+type GettextFile struct {
+	Path     string
+	Reload   bool
+	Interval time.Duration
+	Clock    Clock
+
+	mutex   sync.Mutex
+	catalog *GettextCatalog
+	modTime time.Time
+	checked time.Time
+}
+
+// OpenGettext loads the .po or .mo file, see LoadGettext.
+func OpenGettext(path string, reload bool) (*GettextFile, error) {
+	f := &GettextFile{Path: path, Reload: reload, Interval: time.Second, Clock: SystemClock}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if f.catalog, err = LoadGettext(path); err != nil {
+		return nil, err
+	}
+	f.modTime, f.checked = info.ModTime(), f.Clock.Now()
+	return f, nil
+}
+
+// Catalog returns the catalog, reloading the file first if it has changed.
+// A file failing to load keeps the previous catalog until it is fixed.
+func (f *GettextFile) Catalog() *GettextCatalog {
+	if !f.Reload {
+		return f.catalog // never changes
+	}
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if now := f.Clock.Now(); now.Sub(f.checked) >= f.Interval {
+		f.checked = now
+		if info, err := os.Stat(f.Path); err == nil && !info.ModTime().Equal(f.modTime) {
+			if catalog, err := LoadGettext(f.Path); err == nil {
+				f.catalog, f.modTime = catalog, info.ModTime()
+			}
+		}
+	}
+	return f.catalog
+}
+
+func (f *GettextFile) Translation(key string) (string, bool) {
+	return f.Catalog().Translation(key)
+}
+
+func (f *GettextFile) PluralForm(n int) (int, bool) {
+	return f.Catalog().PluralForm(n)
+}
+
+// LoadCatalogs loads catalogs of the gettext files in the directory named by their locales,
+// e.g. ru_RU.po or de.mo. The files are reloaded on change if reload is set; see GettextFile.
+func LoadCatalogs(dir, defaultLocale string, reload bool) (*Catalogs, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	catalogs := NewCatalogs(defaultLocale)
+	for _, info := range files {
+		ext := filepath.Ext(info.Name())
+		if info.IsDir() || ext != ".po" && ext != ".mo" {
+			continue
+		}
+		locale := strings.TrimSuffix(info.Name(), ext)
+		if catalogs.Catalog(locale) != nil {
+			return nil, fmt.Errorf("%s: more than one catalog of locale %s", dir, locale)
+		}
+		f, err := OpenGettext(filepath.Join(dir, info.Name()), reload)
+		if err != nil {
+			return nil, err
+		}
+		catalogs.Add(locale, f)
+	}
+	return catalogs, nil
+}
+
+// parsePluralExpr compiles the C expression of a Plural-Forms header, e.g.
+// "n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2".
+func parsePluralExpr(expr string) (rule PluralRule, err error) {
+	p := &pluralParser{input: strings.TrimSpace(expr)}
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(pluralError); ok {
+				rule, err = nil, e
+				return
+			}
+			panic(r)
+		}
+	}()
+	rule = p.ternary()
+	if p.skipSpace(); p.pos < len(p.input) {
+		p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	return rule, nil
+}
+
+type pluralError string
+
+func (e pluralError) Error() string { return string(e) }
+
+type pluralParser struct {
+	input string
+	pos   int
+}
+
+func (p *pluralParser) errorf(format string, args ...interface{}) {
+	panic(pluralError(fmt.Sprintf(format, args...)))
+}
+
+func (p *pluralParser) skipSpace() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// accept consumes the operator if it is next.
+func (p *pluralParser) accept(op string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.input[p.pos:], op) {
+		return false
+	}
+	// "<" and ">" must not take the first character of "<=" and ">=", "!" of "!=" or "=" of "=="
+	if len(op) == 1 && p.pos+1 < len(p.input) && p.input[p.pos+1] == '=' && strings.Contains("<>!=", op) {
+		return false
+	}
+	p.pos += len(op)
+	return true
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (p *pluralParser) ternary() PluralRule {
+	cond := p.binary(0)
+	if !p.accept("?") {
+		return cond
+	}
+	then := p.ternary()
+	if !p.accept(":") {
+		p.errorf("expected ':' in %q", p.input)
+	}
+	otherwise := p.ternary()
+	return func(n int) int {
+		if cond(n) != 0 {
+			return then(n)
+		}
+		return otherwise(n)
+	}
+}
+
+// pluralOperators are binary operators by precedence, the lowest first.
+var pluralOperators = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *pluralParser) binary(level int) PluralRule {
+	if level == len(pluralOperators) {
+		return p.unary()
+	}
+	left := p.binary(level + 1)
+	for {
+		op := ""
+		for _, o := range pluralOperators[level] {
+			if p.accept(o) {
+				op = o
+				break
+			}
+		}
+		if op == "" {
+			return left
+		}
+		l, r := left, p.binary(level+1)
+		switch op {
+		case "||":
+			left = func(n int) int { return boolInt(l(n) != 0 || r(n) != 0) }
+		case "&&":
+			left = func(n int) int { return boolInt(l(n) != 0 && r(n) != 0) }
+		case "==":
+			left = func(n int) int { return boolInt(l(n) == r(n)) }
+		case "!=":
+			left = func(n int) int { return boolInt(l(n) != r(n)) }
+		case "<=":
+			left = func(n int) int { return boolInt(l(n) <= r(n)) }
+		case ">=":
+			left = func(n int) int { return boolInt(l(n) >= r(n)) }
+		case "<":
+			left = func(n int) int { return boolInt(l(n) < r(n)) }
+		case ">":
+			left = func(n int) int { return boolInt(l(n) > r(n)) }
+		case "+":
+			left = func(n int) int { return l(n) + r(n) }
+		case "-":
+			left = func(n int) int { return l(n) - r(n) }
+		case "*":
+			left = func(n int) int { return l(n) * r(n) }
+		case "/", "%":
+			div := op == "/"
+			left = func(n int) int {
+				d := r(n)
+				if d == 0 {
+					return 0
+				}
+				if div {
+					return l(n) / d
+				}
+				return l(n) % d
+			}
+		}
+	}
+}
+
+func (p *pluralParser) unary() PluralRule {
+	if p.accept("!") {
+		operand := p.unary()
+		return func(n int) int { return boolInt(operand(n) == 0) }
+	}
+	p.skipSpace()
+	switch {
+	case p.accept("("):
+		expr := p.ternary()
+		if !p.accept(")") {
+			p.errorf("expected ')' in %q", p.input)
+		}
+		return expr
+	case p.accept("n"):
+		return func(n int) int { return n }
+	}
+	start := p.pos
+	for p.pos < len(p.input) && p.input[p.pos] >= '0' && p.input[p.pos] <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos == len(p.input) {
+			p.errorf("unexpected end of %q", p.input)
+		}
+		p.errorf("unexpected %q", p.input[p.pos:])
+	}
+	value, err := strconv.Atoi(p.input[start:p.pos])
+	if err != nil {
+		p.errorf("invalid number %s", p.input[start:p.pos])
+	}
+	return func(n int) int { return value }
+}
@@ -0,0 +1,306 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testPO = `# Russian translations
+msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+#: index.html:3
+msgid "Open"
+msgstr "Открыть"
+
+msgctxt "menu"
+msgid "Open"
+msgstr "Открытие"
+
+#, c-format
+msgid "%d message"
+msgid_plural "%d messages"
+msgstr[0] "%d сообщение"
+msgstr[1] "%d сообщения"
+msgstr[2] "%d сообщений"
+
+msgid ""
+"Multi\n"
+"line"
+msgstr "Много\n"
+"строк \"в кавычках\""
+
+#, fuzzy
+msgid "Fuzzy"
+msgstr "Неточно"
+
+msgid "Untranslated"
+msgstr ""
+
+#~ msgid "Obsolete"
+#~ msgstr "Устарело"
+`
+
+func TestReadPO(t *testing.T) {
+	catalog, err := ReadPO(strings.NewReader(testPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Messages{
+		"Open":         "Открыть",
+		"menu\x04Open": "Открытие",
+		"%d message":   "%d сообщение\x00%d сообщения\x00%d сообщений",
+		"Multi\nline":  "Много\nстрок \"в кавычках\"",
+	}
+	if len(catalog.Messages) != len(expected) {
+		t.Errorf("expected %d messages, got %d: %q", len(expected), len(catalog.Messages), catalog.Messages)
+	}
+	for key, translation := range expected {
+		if catalog.Messages[key] != translation {
+			t.Errorf("%q: expected %q, got %q", key, translation, catalog.Messages[key])
+		}
+	}
+	if catalog.Header["Language"] != "ru" {
+		t.Errorf("unexpected header: %v", catalog.Header)
+	}
+	for n, form := range map[int]int{1: 0, 3: 1, 5: 2, 11: 2, 21: 0} {
+		if f, ok := catalog.PluralForm(n); !ok || f != form {
+			t.Errorf("expected form %d for %d, got %d, %v", form, n, f, ok)
+		}
+	}
+}
+
+func TestReadPOErrors(t *testing.T) {
+	for po, err := range map[string]string{
+		`msgid "a"`:                    `msgid "a" without msgstr`,
+		"msgid \"a\"\nmsgstr \"b":      "invalid string",
+		"\"a\"":                        "unexpected string",
+		"msgid \"a\"\nmsgstr[x] \"b\"": "invalid msgstr index",
+		"msgid \"\"\nmsgstr \"Plural-Forms: nplurals=2; plural=n>;\\n\"": "invalid Plural-Forms",
+		"msgid \"a\"\nmsgstr \"b\"\nfoo":                                 `unexpected "foo"`,
+	} {
+		if _, e := ReadPO(strings.NewReader(po)); e == nil || !strings.Contains(e.Error(), err) {
+			t.Errorf("%q: expected error %q, got %v", po, err, e)
+		}
+	}
+}
+
+// writeMO returns the messages compiled into a .mo file of the byte order.
+func writeMO(messages Messages, order binary.ByteOrder) []byte {
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var header, strs bytes.Buffer
+	n := uint32(len(keys))
+	originals, translations := uint32(28), 28+8*n
+	offset := translations + 8*n
+	binary.Write(&header, order, []uint32{0x950412de, 0, n, originals, translations, 0, 0})
+	var tables [2][]uint32
+	for i, strings := range [2]func(key string) string{
+		func(key string) string { return key },
+		func(key string) string { return messages[key] },
+	} {
+		for _, key := range keys {
+			s := strings(key)
+			tables[i] = append(tables[i], uint32(len(s)), offset+uint32(strs.Len()))
+			strs.WriteString(s + "\x00")
+		}
+	}
+	binary.Write(&header, order, tables[0])
+	binary.Write(&header, order, tables[1])
+	return append(header.Bytes(), strs.Bytes()...)
+}
+
+func TestReadMO(t *testing.T) {
+	messages := Messages{
+		"":                    "Language: pl\nPlural-Forms: nplurals=3; plural=(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n",
+		"File":                "Plik",
+		"menu\x04File":        "Plik menu",
+		"%d file\x00%d files": "%d plik\x00%d pliki\x00%d plików",
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		catalog, err := ReadMO(bytes.NewReader(writeMO(messages, order)))
+		if err != nil {
+			t.Fatalf("%v: %v", order, err)
+		}
+		for key, translation := range map[string]string{
+			"File":         "Plik",
+			"menu\x04File": "Plik menu",
+			"%d file":      "%d plik\x00%d pliki\x00%d plików",
+		} {
+			if tr, ok := catalog.Translation(key); !ok || tr != translation {
+				t.Errorf("%v: %q: expected %q, got %q", order, key, translation, tr)
+			}
+		}
+		if catalog.Header["Language"] != "pl" {
+			t.Errorf("%v: unexpected header: %v", order, catalog.Header)
+		}
+		if form, _ := catalog.PluralForm(22); form != 1 {
+			t.Errorf("%v: expected form 1 for 22, got %d", order, form)
+		}
+	}
+	if _, err := ReadMO(strings.NewReader("not a catalog at all")); err == nil || !strings.Contains(err.Error(), "bad magic number") {
+		t.Errorf("expected bad magic number, got %v", err)
+	}
+	if _, err := ReadMO(bytes.NewReader(writeMO(messages, binary.LittleEndian)[:40])); err == nil {
+		t.Error("expected an error reading a truncated file")
+	}
+}
+
+func TestParsePluralForms(t *testing.T) {
+	for header, forms := range map[string][]int{
+		"nplurals=1; plural=0;":                       {0, 0, 0, 0, 0, 0},
+		"nplurals=2; plural=(n != 1);":                {1, 0, 1, 1, 1, 1},
+		"nplurals=2; plural=n>1;":                     {0, 0, 1, 1, 1, 1},
+		"nplurals=2; plural=!(n==1);":                 {1, 0, 1, 1, 1, 1},
+		"nplurals=3; plural=n==1 ? 0 : n==2 ? 1 : 2;": {2, 0, 1, 2, 2, 2},
+		"nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);": {2, 0, 1, 2, 2, 0},
+	} {
+		nplurals, rule, err := ParsePluralForms(header)
+		if err != nil {
+			t.Errorf("%q: %v", header, err)
+			continue
+		}
+		if nplurals < 1 {
+			t.Errorf("%q: unexpected nplurals %d", header, nplurals)
+		}
+		for i, n := range []int{0, 1, 2, 5, 11, 21} {
+			if form := rule(n); form != forms[i] {
+				t.Errorf("%q: expected form %d for %d, got %d", header, forms[i], n, form)
+			}
+		}
+	}
+	for _, header := range []string{"", "nplurals=2;", "nplurals=x; plural=n!=1;", "nplurals=2; plural=(n!=1;", "nplurals=2; plural=n ? 1;", "nplurals=2; plural=n @ 1;"} {
+		if _, _, err := ParsePluralForms(header); err == nil {
+			t.Errorf("%q: expected an error", header)
+		}
+	}
+}
+
+func TestLoadCatalogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gettext")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ruPO := filepath.Join(dir, "ru.po")
+	if err = ioutil.WriteFile(ruPO, []byte(testPO), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "pl_PL.mo"), writeMO(Messages{"Open": "Otwórz"}, binary.LittleEndian), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(filepath.Join(dir, "README"), []byte("not a catalog"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	catalogs, err := LoadCatalogs(dir, "en", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ru, pl := catalogs.I18n("ru_RU"), catalogs.I18n("pl_PL")
+	for _, test := range []struct{ text, expected string }{
+		{ru.GetText("Open"), "Открыть"},
+		{PGetText(ru, "menu", "Open"), "Открытие"},
+		{PGetText(ru, "toolbar", "Open"), "Open"},
+		{ru.NGetText("%d message", "%d messages", 22), "22 сообщения"},
+		{NPGetText(ru, "inbox", "%d message", "%d messages", 22), "22 messages"},
+		{pl.GetText("Open"), "Otwórz"},
+		{PGetText(noContextI18n{}, "menu", "Open"), "Open!"},
+	} {
+		if test.text != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, test.text)
+		}
+	}
+
+	// reload in development
+	f, err := OpenGettext(ruPO, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock := NewFakeClock(time.Now())
+	f.Clock, f.checked = clock, clock.Now()
+	edited := strings.Replace(testPO, `msgstr "Открыть"`, `msgstr "Открой"`, 1)
+	if err = ioutil.WriteFile(ruPO, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(time.Minute)
+	if err = os.Chtimes(ruPO, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := f.Translation("Open"); text != "Открыть" {
+		t.Errorf("the file should not be checked before the interval, got %q", text)
+	}
+	clock.Advance(time.Second)
+	if text, _ := f.Translation("Open"); text != "Открой" {
+		t.Errorf("expected the edited translation, got %q", text)
+	}
+	if err = ioutil.WriteFile(ruPO, []byte(`msgid "broken`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime = modTime.Add(time.Minute)
+	if err = os.Chtimes(ruPO, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second)
+	if text, _ := f.Translation("Open"); text != "Открой" {
+		t.Errorf("a broken file should keep the previous catalog, got %q", text)
+	}
+
+	if err = ioutil.WriteFile(filepath.Join(dir, "pl_PL.po"), []byte(testPO), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LoadCatalogs(dir, "en", false); err == nil || !strings.Contains(err.Error(), "more than one catalog of locale pl_PL") {
+		t.Errorf("expected an error of duplicate catalogs, got %v", err)
+	}
+}
+
+// noContextI18n does not implement ContextI18n.
+type noContextI18n struct{}
+
+func (noContextI18n) GetText(key string) string { return key + "!" }
+
+func (noContextI18n) NGetText(singular, plural string, n int) string { return plural }
+
+func (noContextI18n) Format(message string, args map[string]interface{}) string { return message }
+
+func TestParsePO(t *testing.T) {
+	po, err := ParsePO(strings.NewReader(testPO))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(po.Entries) != 8 {
+		t.Fatalf("expected 8 entries, got %d", len(po.Entries))
+	}
+	if field := po.HeaderField("Language"); field != "ru" {
+		t.Errorf("unexpected Language header %q", field)
+	}
+	if e := po.Entries[1]; e.ID != "Open" || strings.Join(e.References, " ") != "index.html:3" || e.Comments != nil {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	if e := po.Entries[0]; strings.Join(e.Comments, "|") != "Russian translations" {
+		t.Errorf("unexpected header comments %q", e.Comments)
+	}
+	if e := po.Entries[7]; e.ID != "Obsolete" || !e.Obsolete || e.Translation() != "Устарело" {
+		t.Errorf("unexpected obsolete entry %+v", e)
+	}
+	var b bytes.Buffer
+	if err = po.Write(&b); err != nil {
+		t.Fatal(err)
+	}
+	// strings of more than one line start with an empty one
+	expected := strings.Replace(testPO, "msgstr \"Много\\n\"\n", "msgstr \"\"\n\"Много\\n\"\n", 1)
+	if b.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, b.String())
+	}
+}
@@ -0,0 +1,164 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// Handler serves templates over HTTP: it picks the locale, creates the template for the request,
+// gets its data and renders it.
+//
+// The response is buffered until the flush policy flushes it. If GetData or Render fails
+// before anything is flushed the buffered output is discarded and the error is responded
+// with its status code and the error template instead.
+type Handler struct {
+	// Template creates the template for the request in the locale, e.g. by building the payload
+	// from the request and calling NewIndex_html(locale, payload).
+	Template func(r *http.Request, locale string) (Template, error)
+
+	// ErrorTemplate creates the template rendering an error with the status code;
+	// plain status text is responded if it is nil or fails.
+	ErrorTemplate func(r *http.Request, locale string, status int, err error) (Template, error)
+
+	Locales     []string    // supported locales, the default one first
+	ContentType string      // "text/html; charset=utf-8" if empty
+	FlushPolicy FlushPolicy // FlushNever buffers the whole response
+	OutOfOrder  bool        // render slow components out of order, see RenderContext.WithOutOfOrder
+	Scheduler   *Scheduler  // schedules data loading of the renders; DefaultScheduler if nil
+}
+
+// NewHandler creates a handler serving templates created by the function.
+func NewHandler(template func(r *http.Request, locale string) (Template, error), locales ...string) *Handler {
+	return &Handler{Template: template, Locales: locales}
+}
+
+// StatusError is an error the Handler responds with the status code, e.g. http.StatusNotFound.
+type StatusError struct {
+	Code int
+	Err  error
+}
+
+func (e StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e StatusError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns the status code the Handler responds with for the error.
+func StatusCode(err error) int {
+	var statusError StatusError
+	var missingParam MissingParamError
+	switch {
+	case errors.As(err, &statusError):
+		return statusError.Code
+	case errors.As(err, &missingParam):
+		return http.StatusBadRequest
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	locale := h.locale(r)
+	response := &responseBuffer{ResponseWriter: w}
+	err := h.serve(response, r, locale, h.Template, true)
+	if err == nil {
+		response.Flush()
+		return
+	}
+	if response.flushed || errors.Is(err, context.Canceled) {
+		return // The status is sent already, or there is nobody to send it to.
+	}
+	status := StatusCode(err)
+	response.Reset()
+	if h.ErrorTemplate != nil {
+		if h.serve(response, r, locale, func(r *http.Request, locale string) (Template, error) {
+			return h.ErrorTemplate(r, locale, status, err)
+		}, false) == nil {
+			w.WriteHeader(status)
+			response.Flush()
+			return
+		}
+		response.Reset()
+	}
+	http.Error(w, http.StatusText(status), status)
+}
+
+// serve renders the template for the request to the response. Unless it streams, the response is buffered
+// whole and components are rendered in order, e.g. for the error template to be sent after the status code.
+func (h *Handler) serve(response *responseBuffer, r *http.Request, locale string, template func(r *http.Request, locale string) (Template, error), stream bool) error {
+	t, err := template(r, locale)
+	if err != nil {
+		return err
+	}
+	scheduler := h.Scheduler
+	if scheduler == nil {
+		scheduler = DefaultScheduler
+	}
+	header := response.Header()
+	if h.ContentType != "" {
+		header.Set("Content-Type", h.ContentType)
+	} else {
+		header.Set("Content-Type", "text/html; charset=utf-8")
+	}
+	if locale != "" {
+		header.Set("Content-Language", strings.Replace(locale, "_", "-", -1))
+	}
+	c := NewRenderContext(scheduler.NewRender(r.Context()), response)
+	if stream {
+		c = c.WithFlushPolicy(h.FlushPolicy)
+		if h.OutOfOrder {
+			c = c.WithOutOfOrder()
+		}
+	}
+	if err := t.GetData(c.Context); err != nil {
+		return err
+	}
+	return t.Render(c)
+}
+
+// locale returns the supported locale negotiated from the Accept-Language header of the request.
+func (h *Handler) locale(r *http.Request) string {
+	return NegotiateLocale(r.Header.Get("Accept-Language"), h.Locales)
+}
+
+// responseBuffer buffers the response until it is flushed.
+// Once sending to the client fails, writes return the error so that the render stops.
+type responseBuffer struct {
+	http.ResponseWriter
+	bytes.Buffer
+	flushed bool  // whether anything is sent to the client
+	err     error // error of sending to the client
+}
+
+func (b *responseBuffer) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.Buffer.Write(p)
+}
+
+// Flush sends the buffered output to the client.
+func (b *responseBuffer) Flush() {
+	if b.err != nil {
+		return
+	}
+	if b.Len() > 0 {
+		b.flushed = true
+		_, b.err = b.ResponseWriter.Write(b.Bytes())
+		b.Reset()
+		if b.err != nil {
+			return
+		}
+	}
+	if flusher, ok := b.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
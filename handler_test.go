@@ -0,0 +1,137 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// handlerTemplate renders its output, failing with err after it.
+type handlerTemplate struct {
+	output  string
+	err     error
+	dataErr error
+}
+
+func (t handlerTemplate) Name() string   { return "test.html" }
+func (t handlerTemplate) Path() string   { return "test.html" }
+func (t handlerTemplate) Source() string { return "" }
+
+func (t handlerTemplate) GetData(ctx context.Context) error {
+	if MemoFrom(ctx) == nil {
+		return errors.New("GetData should get the context of the render")
+	}
+	return t.dataErr
+}
+
+func (t handlerTemplate) Render(c RenderContext) error {
+	c.WriteString(t.output)
+	return t.err
+}
+
+func TestHandler(t *testing.T) {
+	notFound := StatusError{Code: http.StatusNotFound, Err: errors.New("no such author")}
+	errorTemplate := func(r *http.Request, locale string, status int, err error) (Template, error) {
+		return handlerTemplate{output: locale + ": " + err.Error()}, nil
+	}
+	for _, test := range []struct {
+		name           string
+		template       handlerTemplate
+		templateErr    error
+		flushPolicy    FlushPolicy
+		errorTemplate  func(r *http.Request, locale string, status int, err error) (Template, error)
+		acceptLanguage string
+		status         int
+		body           string
+		contentType    string
+	}{
+		{name: "ok", template: handlerTemplate{output: "<p>ok</p>"}, acceptLanguage: "de, ru-RU",
+			status: http.StatusOK, body: "<p>ok</p>", contentType: "text/html; charset=utf-8"},
+		{name: "template error", templateErr: notFound,
+			status: http.StatusNotFound, body: "Not Found\n", contentType: "text/plain; charset=utf-8"},
+		{name: "missing param", template: handlerTemplate{dataErr: MissingParamError{"test.html", "p1"}},
+			status: http.StatusBadRequest, body: "Bad Request\n"},
+		{name: "render error", template: handlerTemplate{output: "<p>", err: errors.New("failed")},
+			status: http.StatusInternalServerError, body: "Internal Server Error\n"},
+		{name: "error template", template: handlerTemplate{output: "<p>", err: notFound}, errorTemplate: errorTemplate,
+			acceptLanguage: "ru-RU", status: http.StatusNotFound, body: "ru_RU: no such author", contentType: "text/html; charset=utf-8"},
+		{name: "error template flush", template: handlerTemplate{dataErr: notFound}, errorTemplate: errorTemplate, flushPolicy: FlushPolicy{Threshold: 10},
+			acceptLanguage: "ru-RU", status: http.StatusNotFound, body: "ru_RU: no such author"},
+		{name: "flushed", template: handlerTemplate{output: "<p>", err: notFound}, flushPolicy: FlushPolicy{Threshold: 1},
+			status: http.StatusOK, body: "<p>"},
+	} {
+		handler := NewHandler(func(r *http.Request, locale string) (Template, error) {
+			return test.template, test.templateErr
+		}, "en_US", "ru_RU")
+		handler.FlushPolicy = test.flushPolicy
+		handler.ErrorTemplate = test.errorTemplate
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept-Language", test.acceptLanguage)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != test.status {
+			t.Errorf("%s: expected status %d, got %d", test.name, test.status, recorder.Code)
+		}
+		if body := recorder.Body.String(); body != test.body {
+			t.Errorf("%s: expected body %q, got %q", test.name, test.body, body)
+		}
+		if contentType := recorder.Header().Get("Content-Type"); test.contentType != "" && contentType != test.contentType {
+			t.Errorf("%s: expected Content-Type %q, got %q", test.name, test.contentType, contentType)
+		}
+	}
+}
+
+func TestHandlerLocale(t *testing.T) {
+	handler := NewHandler(nil, "en_US", "ru_RU")
+	for acceptLanguage, locale := range map[string]string{
+		"":                   "en_US",
+		"ru-RU":              "ru_RU",
+		"de-DE, ru-ru;q=0.5": "ru_RU",
+		"de":                 "en_US",
+	} {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept-Language", acceptLanguage)
+		if l := handler.locale(request); l != locale {
+			t.Errorf("%q: expected locale %s, got %s", acceptLanguage, locale, l)
+		}
+	}
+}
+
+// failingResponse fails to send anything to the client.
+type failingResponse struct {
+	*httptest.ResponseRecorder
+}
+
+func (r failingResponse) Write(p []byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+// countingTemplate writes its output until a write fails, counting the writes that succeed.
+type countingTemplate struct {
+	handlerTemplate
+	writes *int
+}
+
+func (t countingTemplate) Render(c RenderContext) error {
+	for i := 0; i < 10; i++ {
+		if _, err := c.WriteString(t.output); err != nil {
+			return err
+		}
+		*t.writes++
+	}
+	return nil
+}
+
+func TestHandlerWriteError(t *testing.T) {
+	var writes int
+	handler := NewHandler(func(r *http.Request, locale string) (Template, error) {
+		return countingTemplate{handlerTemplate{output: "<p>"}, &writes}, nil
+	})
+	handler.FlushPolicy = FlushPolicy{Threshold: 1}
+	handler.ServeHTTP(failingResponse{httptest.NewRecorder()}, httptest.NewRequest("GET", "/", nil))
+	if writes != 1 {
+		t.Errorf("the render should stop once sending to the client fails, got %d writes", writes)
+	}
+}
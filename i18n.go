@@ -0,0 +1,281 @@
+package templates
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locale is implemented by I18n implementations knowing their locale.
+type Locale interface {
+	Locale() string
+}
+
+// ContextI18n is implemented by I18n implementations translating source strings in a context,
+// e.g. "menu" or "verb", telling apart translations of the same source string.
+type ContextI18n interface {
+	PGetText(context, key string) string
+	NPGetText(context, singular, plural string, n int) string
+}
+
+// PGetText translates the source string in the context, or without it if the i18n does not implement ContextI18n.
+func PGetText(i18n I18n, context, key string) string {
+	if c, ok := i18n.(ContextI18n); ok {
+		return c.PGetText(context, key)
+	}
+	return i18n.GetText(key)
+}
+
+// NPGetText translates the plural form for n in the context, or without it if the i18n does not implement ContextI18n.
+func NPGetText(i18n I18n, context, singular, plural string, n int) string {
+	if c, ok := i18n.(ContextI18n); ok {
+		return c.NPGetText(context, singular, plural, n)
+	}
+	return i18n.NGetText(singular, plural, n)
+}
+
+// LocaleOf returns the locale of the i18n, or an empty string if it does not implement Locale.
+func LocaleOf(i18n I18n) string {
+	if l, ok := i18n.(Locale); ok {
+		return l.Locale()
+	}
+	return ""
+}
+
+// LocaleIndex returns 1 + the index of the locale of the i18n in the locales, or 0 if it is not there.
+// Code generated with inlined translations picks the render path of the locale by it.
+func LocaleIndex(i18n I18n, locales ...string) int {
+	locale := LocaleOf(i18n)
+	for i, l := range locales {
+		if l == locale {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// ParseAcceptLanguage returns language tags of an Accept-Language header, most preferred first.
+// Tags of equal quality keep their order; tags with q=0 and the wildcard are left out.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		tag string
+		q   float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				var err error
+				if q, err = strconv.ParseFloat(param[2:], 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{tag, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+	result := make([]string, len(tags))
+	for i, tag := range tags {
+		result[i] = tag.tag
+	}
+	return result
+}
+
+// NegotiateLocale returns the available locale matching the Accept-Language header best.
+// Each accepted language, in order of preference, matches a locale of the same language and region,
+// then a locale of its base language, e.g. "de-CH" matches "de_CH", then "de" or "de_DE".
+// The first of the locales is the default returned if no language matches.
+func NegotiateLocale(acceptLanguage string, locales []string) string {
+	if len(locales) == 0 {
+		return ""
+	}
+	for _, tag := range ParseAcceptLanguage(acceptLanguage) {
+		tag = normalizeLocale(tag)
+		for _, locale := range locales {
+			if normalizeLocale(locale) == tag {
+				return locale
+			}
+		}
+		base := baseLanguage(tag)
+		for _, locale := range locales {
+			if normalizeLocale(locale) == base {
+				return locale
+			}
+		}
+		for _, locale := range locales {
+			if baseLanguage(normalizeLocale(locale)) == base {
+				return locale
+			}
+		}
+	}
+	return locales[0]
+}
+
+// LocaleChain returns the locales translations for the locale are looked up in:
+// the locale itself, its base language and the default locale, e.g. ru_RU, ru, en_US.
+func LocaleChain(locale, defaultLocale string) []string {
+	chain := []string{locale}
+	if base := baseLanguage(locale); base != locale {
+		chain = append(chain, base)
+	}
+	if defaultLocale != "" && defaultLocale != locale {
+		chain = append(chain, defaultLocale)
+	}
+	return chain
+}
+
+// normalizeLocale returns the locale or language tag in lower case with "_" separating the region,
+// e.g. "ru_ru" for "ru-RU".
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(locale, "-", "_", -1))
+}
+
+// baseLanguage returns the language of the locale without the region, e.g. "ru" for "ru_RU".
+func baseLanguage(locale string) string {
+	if i := strings.IndexAny(locale, "_-"); i >= 0 {
+		return locale[:i]
+	}
+	return locale
+}
+
+// Catalog holds translations of source strings to one locale.
+// Plural forms of a translation are separated by NUL, as in gettext .mo files,
+// in the order of PluralForm; the key of a plural message is its singular source string.
+// Keys of messages in a context are prefixed with it, see ContextKey.
+type Catalog interface {
+	// Translation returns the translation of the source string; ok is false if it is not translated.
+	Translation(key string) (translation string, ok bool)
+}
+
+// Messages is a catalog of translations kept in a map, e.g.
+//
+//	Messages{"%d message": "%d сообщение\x00%d сообщения\x00%d сообщений"}
+type Messages map[string]string
+
+func (m Messages) Translation(key string) (string, bool) {
+	translation, ok := m[key]
+	return translation, ok
+}
+
+// Catalogs holds catalogs of the available locales.
+type Catalogs struct {
+	Default  string // locale of the source strings, the last resort of locale chains
+	catalogs map[string]Catalog
+}
+
+func NewCatalogs(defaultLocale string) *Catalogs {
+	return &Catalogs{Default: defaultLocale, catalogs: make(map[string]Catalog)}
+}
+
+// Add sets the catalog of the locale.
+func (c *Catalogs) Add(locale string, catalog Catalog) {
+	c.catalogs[locale] = catalog
+}
+
+// Catalog returns the catalog of the locale, or nil.
+func (c *Catalogs) Catalog(locale string) Catalog {
+	return c.catalogs[locale]
+}
+
+// Locales returns the available locales, the default one first and the others sorted.
+func (c *Catalogs) Locales() []string {
+	locales := []string{c.Default}
+	for locale := range c.catalogs {
+		if locale != c.Default {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales[1:])
+	return locales
+}
+
+// Negotiate returns the available locale matching the Accept-Language header best, see NegotiateLocale.
+func (c *Catalogs) Negotiate(acceptLanguage string) string {
+	return NegotiateLocale(acceptLanguage, c.Locales())
+}
+
+// I18n returns translations to the locale. GetText looks source strings up in catalogs
+// of the locale chain, see LocaleChain, and returns the source string if none translates it.
+func (c *Catalogs) I18n(locale string) I18n {
+	i18n := &chainI18n{locale: locale}
+	for _, l := range LocaleChain(locale, c.Default) {
+		if catalog := c.catalogs[l]; catalog != nil {
+			i18n.chain = append(i18n.chain, localeCatalog{l, catalog})
+		}
+	}
+	return i18n
+}
+
+type localeCatalog struct {
+	locale  string
+	catalog Catalog
+}
+
+// chainI18n translates with the first catalog of the chain having a translation.
+type chainI18n struct {
+	locale string
+	chain  []localeCatalog
+}
+
+func (i18n *chainI18n) Locale() string {
+	return i18n.locale
+}
+
+func (i18n *chainI18n) GetText(key string) string {
+	return i18n.PGetText("", key)
+}
+
+func (i18n *chainI18n) NGetText(singular, plural string, n int) string {
+	return i18n.NPGetText("", singular, plural, n)
+}
+
+func (i18n *chainI18n) PGetText(context, key string) string {
+	for _, c := range i18n.chain {
+		if translation, ok := c.catalog.Translation(ContextKey(context, key)); ok && translation != "" {
+			if i := strings.IndexByte(translation, 0); i >= 0 {
+				return translation[:i]
+			}
+			return translation
+		}
+	}
+	return key
+}
+
+// NPGetText picks the plural form by the rule of the catalog translating the message, see PluralCatalog,
+// or by the rule of its locale; the source strings are in English.
+func (i18n *chainI18n) NPGetText(context, singular, plural string, n int) string {
+	for _, c := range i18n.chain {
+		if translation, ok := c.catalog.Translation(ContextKey(context, singular)); ok {
+			forms := strings.Split(translation, "\x00")
+			form, ok := 0, false
+			if pc, isPlural := c.catalog.(PluralCatalog); isPlural {
+				form, ok = pc.PluralForm(n)
+			}
+			if !ok {
+				form = PluralForm(c.locale, n)
+			}
+			if form < len(forms) && forms[form] != "" {
+				return FormatPlural(forms[form], n)
+			}
+		}
+	}
+	if PluralForm("en", n) == 0 {
+		return FormatPlural(singular, n)
+	}
+	return FormatPlural(plural, n)
+}
+
+func (i18n *chainI18n) Format(message string, args map[string]interface{}) string {
+	return FormatMessage(message, args)
+}
@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for header, tags := range map[string]string{
+		"":                                   "[]",
+		"ru-RU":                              "[ru-RU]",
+		"en;q=0.5, ru-RU, ru;q=0.8, *;q=0.1": "[ru-RU ru en]",
+		"de;q=0, fr;q=bad, es ; q=0.3":       "[es]",
+		"uk, be":                             "[uk be]",
+	} {
+		if result := fmt.Sprint(ParseAcceptLanguage(header)); result != tags {
+			t.Errorf("%q: expected %s, got %s", header, tags, result)
+		}
+	}
+}
+
+func TestNegotiateLocale(t *testing.T) {
+	locales := []string{"en_US", "de", "ru_RU", "pt_PT"}
+	for acceptLanguage, locale := range map[string]string{
+		"":                      "en_US",
+		"ru-RU":                 "ru_RU",
+		"ru":                    "ru_RU",
+		"de-CH, ru;q=0.9":       "de",
+		"fr, pt-BR;q=0.5":       "pt_PT",
+		"ru;q=0.1, en-GB;q=0.5": "en_US",
+		"ja":                    "en_US",
+	} {
+		if result := NegotiateLocale(acceptLanguage, locales); result != locale {
+			t.Errorf("%q: expected %s, got %s", acceptLanguage, locale, result)
+		}
+	}
+}
+
+func TestCatalogsI18n(t *testing.T) {
+	catalogs := NewCatalogs("en_US")
+	catalogs.Add("en_US", Messages{"Hi": "Hello"})
+	catalogs.Add("ru", Messages{"Hi": "Привет", "Bye": "Пока"})
+	catalogs.Add("ru_UA", Messages{"Bye": "До свидания", "Empty": ""})
+	if locales := fmt.Sprint(catalogs.Locales()); locales != "[en_US ru ru_UA]" {
+		t.Errorf("unexpected locales: %s", locales)
+	}
+	for _, test := range []struct {
+		locale, key, text string
+	}{
+		{"ru_UA", "Bye", "До свидания"},
+		{"ru_UA", "Hi", "Привет"},    // base language
+		{"ru_UA", "Empty", "Empty"},  // untranslated source string
+		{"de", "Hi", "Hello"},        // default locale
+		{"de", "Unknown", "Unknown"}, // source string
+	} {
+		i18n := catalogs.I18n(test.locale)
+		if text := i18n.GetText(test.key); text != test.text {
+			t.Errorf("%s: expected %q for %q, got %q", test.locale, test.text, test.key, text)
+		}
+		if LocaleOf(i18n) != test.locale {
+			t.Errorf("%s: unexpected locale %s", test.locale, LocaleOf(i18n))
+		}
+	}
+	if locale := catalogs.Negotiate("uk, ru-UA;q=0.9"); locale != "ru_UA" {
+		t.Errorf("expected ru_UA, got %s", locale)
+	}
+}
+
+func TestLocaleIndex(t *testing.T) {
+	catalogs := NewCatalogs("en_US")
+	for locale, index := range map[string]int{"en_US": 1, "ru_RU": 2, "ru": 0} {
+		if i := LocaleIndex(catalogs.I18n(locale), "en_US", "ru_RU"); i != index {
+			t.Errorf("%s: expected %d, got %d", locale, index, i)
+		}
+	}
+}
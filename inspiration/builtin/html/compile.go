@@ -0,0 +1,80 @@
+package html
+
+// Context is the state of the HTML parser at a point of a template tracked at compile time,
+// so that code generators can escape values printed by templates without rewriting them at run time.
+// The zero value is the start of an HTML fragment.
+type Context struct {
+	c context
+}
+
+// AfterText returns the context after static text of a template.
+func (c Context) AfterText(s []byte) Context {
+	for len(s) != 0 && c.c.state != stateError {
+		c1, n := contextAfterText(c.c, s)
+		c.c, s = c1, s[n:]
+	}
+	return c
+}
+
+// Action returns the context after a value printed in the context
+// and the names of Escapers the value has to pass through, in order.
+func (c Context) Action() (Context, []string) {
+	c1, funcs := actionEscapers(c.c, 0, "value")
+	escapers := make([]string, len(funcs))
+	for i, f := range funcs {
+		escapers[i] = escaperNames[f]
+	}
+	return Context{c1}, escapers
+}
+
+// Err returns the error the parser ran into, e.g. a value in an ambiguous URL context.
+// Once it is set, the context does not change.
+func (c Context) Err() *Error {
+	if c.c.state != stateError {
+		return nil
+	}
+	return c.c.err
+}
+
+// IsText reports whether the context is text between tags, the one a template is expected to end in.
+func (c Context) IsText() bool {
+	return c.c.state == stateText
+}
+
+// Equal reports whether the contexts are the same.
+func (c Context) Equal(d Context) bool {
+	return c.c.eq(d.c)
+}
+
+func (c Context) String() string {
+	return c.c.String()
+}
+
+// escaperNames maps names of escapers in funcMap to their names in Escapers.
+var escaperNames = map[string]string{
+	"html_template_attrescaper":     "EscapeAttr",
+	"html_template_commentescaper":  "EscapeComment",
+	"html_template_cssescaper":      "EscapeCSS",
+	"html_template_cssvaluefilter":  "FilterCSSValue",
+	"html_template_htmlnamefilter":  "FilterHTMLName",
+	"html_template_htmlescaper":     "EscapeHTML",
+	"html_template_jsregexpescaper": "EscapeJSRegexp",
+	"html_template_jsstrescaper":    "EscapeJSStr",
+	"html_template_jsvalescaper":    "EscapeJSVal",
+	"html_template_nospaceescaper":  "EscapeUnquotedAttr",
+	"html_template_rcdataescaper":   "EscapeRCDATA",
+	"html_template_srcsetescaper":   "EscapeSrcset",
+	"html_template_urlescaper":      "EscapeURL",
+	"html_template_urlfilter":       "FilterURL",
+	"html_template_urlnormalizer":   "NormalizeURL",
+}
+
+// Escapers maps names Context.Action returns to the functions rendering values safe in their context.
+// Values of content types, e.g. HTML, are passed through unescaped in a matching context.
+var Escapers = map[string]func(args ...interface{}) string{}
+
+func init() {
+	for name, exported := range escaperNames {
+		Escapers[exported] = funcMap[name].(func(...interface{}) string)
+	}
+}
@@ -0,0 +1,49 @@
+package html
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContext(t *testing.T) {
+	tests := []struct {
+		text     string
+		escapers string
+	}{
+		{``, "EscapeHTML"},
+		{`<a href="`, "FilterURL NormalizeURL EscapeAttr"},
+		{`<a href="/search?q=`, "EscapeURL EscapeAttr"},
+		{`<a title=`, "EscapeUnquotedAttr"},
+		{`<a `, "FilterHTMLName"},
+		{`<script>var s = '`, "EscapeJSStr"},
+		{`<button onclick="f(`, "EscapeJSVal EscapeAttr"},
+		{`<p style="color: `, "FilterCSSValue EscapeAttr"},
+		{`<title>`, "EscapeRCDATA"},
+		{`<img srcset="`, "EscapeSrcset EscapeAttr"},
+	}
+	for _, test := range tests {
+		c, escapers := Context{}.AfterText([]byte(test.text)).Action()
+		if err := c.Err(); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.text, err)
+		}
+		if s := strings.Join(escapers, " "); s != test.escapers {
+			t.Errorf("%s: expected escapers %q, got %q", test.text, test.escapers, s)
+		}
+		for _, escaper := range escapers {
+			if Escapers[escaper] == nil {
+				t.Errorf("%s: unknown escaper %s", test.text, escaper)
+			}
+		}
+	}
+
+	c, _ := Context{}.AfterText([]byte(`<script>var v = `)).Action()
+	if c = c.AfterText([]byte(`;</script>`)); !c.IsText() {
+		t.Errorf("expected text context after the script, got %v", c)
+	}
+	if c = c.AfterText([]byte(`<a title=x"`)); c.Err() == nil || !strings.Contains(c.Err().Description, "in unquoted attr") {
+		t.Errorf("expected error of a quote in unquoted attr, got %v", c.Err())
+	}
+	if !c.AfterText([]byte(`</a>`)).Equal(c) {
+		t.Errorf("the error context should not change")
+	}
+}
@@ -58,6 +58,7 @@ var funcMap = template.FuncMap{
 	"html_template_jsvalescaper":    jsValEscaper,
 	"html_template_nospaceescaper":  htmlNospaceEscaper,
 	"html_template_rcdataescaper":   rcdataEscaper,
+	"html_template_srcsetescaper":   srcsetFilterAndEscaper,
 	"html_template_urlescaper":      urlEscaper,
 	"html_template_urlfilter":       urlFilter,
 	"html_template_urlnormalizer":   urlNormalizer,
@@ -140,11 +141,21 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 		// A local variable assignment, not an interpolation.
 		return c
 	}
+	c, s := actionEscapers(c, n.Line, n)
+	if c.state != stateError {
+		e.editActionNode(n, s)
+	}
+	return c
+}
+
+// actionEscapers returns the context after an action n at line printing a value in context c
+// and the names of escapers in funcMap the value has to pass through, in order.
+func actionEscapers(c context, line int, n interface{}) (context, []string) {
 	c = nudge(c)
 	s := make([]string, 0, 3)
 	switch c.state {
 	case stateError:
-		return c
+		return c, nil
 	case stateURL, stateCSSDqStr, stateCSSSqStr, stateCSSDqURL, stateCSSSqURL, stateCSSURL:
 		switch c.urlPart {
 		case urlPartNone:
@@ -162,11 +173,13 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 		case urlPartUnknown:
 			return context{
 				state: stateError,
-				err:   errorf(ErrAmbigContext, n.Line, "%s appears in an ambiguous URL context", n),
-			}
+				err:   errorf(ErrAmbigContext, line, "%s appears in an ambiguous URL context", n),
+			}, nil
 		default:
 			panic(c.urlPart.String())
 		}
+	case stateSrcset:
+		s = append(s, "html_template_srcsetescaper")
 	case stateJS:
 		s = append(s, "html_template_jsvalescaper")
 		// A slash after a value starts a div operator.
@@ -201,8 +214,7 @@ func (e *escaper) escapeAction(c context, n *parse.ActionNode) context {
 	default:
 		s = append(s, "html_template_attrescaper")
 	}
-	e.editActionNode(n, s)
-	return c
+	return c, s
 }
 
 // ensurePipelineContains ensures that the pipeline has commands with
@@ -23,6 +23,7 @@ var transitionFunc = [...]func(context, []byte) (context, int){
 	stateRCDATA:      tSpecialTagEnd,
 	stateAttr:        tAttr,
 	stateURL:         tURL,
+	stateSrcset:      tURL,
 	stateJS:          tJS,
 	stateJSDqStr:     tJSDelimited,
 	stateJSSqStr:     tJSDelimited,
@@ -108,6 +109,8 @@ func tTag(c context, s []byte) (context, int) {
 	switch attrType(string(s[i:j])) {
 	case contentTypeURL:
 		attr = attrURL
+	case contentTypeSrcset:
+		attr = attrSrcset
 	case contentTypeCSS:
 		attr = attrStyle
 	case contentTypeJS:
@@ -153,6 +156,7 @@ var attrStartStates = [...]state{
 	attrScript: stateJS,
 	attrStyle:  stateCSS,
 	attrURL:    stateURL,
+	attrSrcset: stateSrcset,
 }
 
 // tBeforeValue is the context transition function for stateBeforeValue.
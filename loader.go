@@ -0,0 +1,76 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrNotLoaded is passed to a Loader callback when the batch function returned no value for its key.
+var ErrNotLoaded = errors.New("templates: batch function returned no value for the key")
+
+// BatchFunc loads values for all the keys in one call, e.g. a single API request.
+// Keys are unique; values of keys missing from the result are reported as ErrNotLoaded.
+// The call should return the error of ctx once it is done.
+type BatchFunc func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error)
+
+// Loader collects keyed requests of sibling components during one GetData pass
+// and dispatches them as a single call of the batch function.
+//
+// Components call Load from their GetData; the StrongoComponent the loader is registered with
+// dispatches it once GetData of all its components has returned.
+type Loader struct {
+	batch BatchFunc
+
+	mutex     sync.Mutex
+	keys      []interface{}                                        // requested keys in order of the first request
+	callbacks map[interface{}][]func(value interface{}, err error) // callbacks waiting for a key
+}
+
+// NewLoader creates a loader dispatching requests to the batch function.
+func NewLoader(batch BatchFunc) *Loader {
+	return &Loader{
+		batch:     batch,
+		callbacks: make(map[interface{}][]func(value interface{}, err error)),
+	}
+}
+
+// Load requests the value of the key. The callback is called by Dispatch,
+// from another goroutine, with the loaded value or the error of the batch.
+func (l *Loader) Load(key interface{}, callback func(value interface{}, err error)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if _, ok := l.callbacks[key]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.callbacks[key] = append(l.callbacks[key], callback)
+}
+
+// Dispatch calls the batch function once with all the keys requested since the previous dispatch
+// and hands the results to the callbacks. It does nothing if no keys were requested.
+// Callbacks get the error of ctx without calling the batch function if ctx is already done.
+func (l *Loader) Dispatch(ctx context.Context) {
+	l.mutex.Lock()
+	keys, callbacks := l.keys, l.callbacks
+	l.keys, l.callbacks = nil, make(map[interface{}][]func(value interface{}, err error))
+	l.mutex.Unlock()
+
+	if len(keys) == 0 {
+		return
+	}
+	var values map[interface{}]interface{}
+	err := ctx.Err()
+	if err == nil {
+		values, err = l.batch(ctx, keys)
+	}
+	for _, key := range keys {
+		value, ok := values[key]
+		keyErr := err
+		if keyErr == nil && !ok {
+			keyErr = ErrNotLoaded
+		}
+		for _, callback := range callbacks[key] {
+			callback(value, keyErr)
+		}
+	}
+}
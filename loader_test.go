@@ -0,0 +1,92 @@
+package templates
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type loadingComponent struct {
+	loader *Loader
+	key    int
+	value  interface{}
+	err    error
+	done   *sync.WaitGroup
+}
+
+func (c *loadingComponent) GetData(ctx context.Context) error {
+	c.loader.Load(c.key, func(value interface{}, err error) {
+		c.value, c.err = value, err
+		c.done.Done()
+	})
+	return nil
+}
+
+func TestLoader(t *testing.T) {
+	var calls [][]interface{}
+	loader := NewLoader(func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		calls = append(calls, keys)
+		values := make(map[interface{}]interface{})
+		for _, key := range keys {
+			if key != 3 {
+				values[key] = key.(int) * 10
+			}
+		}
+		return values, nil
+	})
+	done := new(sync.WaitGroup)
+	var cards []*loadingComponent
+	var components []IStrongoComponent
+	for _, key := range []int{1, 2, 1, 3} {
+		card := &loadingComponent{loader: loader, key: key, done: done}
+		cards = append(cards, card)
+		components = append(components, card)
+	}
+	done.Add(len(cards))
+	NewStrongoComponent(components, loader).GetData(context.Background())
+	done.Wait()
+
+	if expected := [][]interface{}{{1, 2, 3}}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected batch calls %v, got %v", expected, calls)
+	}
+	for _, card := range cards[:3] {
+		if card.err != nil || card.value != card.key*10 {
+			t.Errorf("key %d: unexpected result %v, %v", card.key, card.value, card.err)
+		}
+	}
+	if card := cards[3]; card.err != ErrNotLoaded {
+		t.Errorf("key %d: expected ErrNotLoaded, got %v, %v", card.key, card.value, card.err)
+	}
+
+	loader.Dispatch(context.Background())
+	if len(calls) != 1 {
+		t.Errorf("dispatch without requests should not call the batch function")
+	}
+}
+
+func TestLoaderError(t *testing.T) {
+	batchErr := errors.New("batch failed")
+	loader := NewLoader(func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		return nil, batchErr
+	})
+	var err error
+	loader.Load("key", func(value interface{}, e error) {
+		err = e
+	})
+	loader.Dispatch(context.Background())
+	if err != batchErr {
+		t.Errorf("expected the batch error, got: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	loader.Load("key", func(value interface{}, e error) {
+		err = e
+	})
+	loader.Dispatch(ctx)
+	if err != context.Canceled {
+		t.Errorf("expected the context error, got: %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Memo shares data fetched by components during one render.
+// Identical in-flight fetches are coalesced, singleflight style,
+// and the result is handed to all components asking for the key.
+//
+// Keys should be of types private to the fetching package, e.g. type authorKey int,
+// to not collide with keys of other packages.
+//
+// Memo is for components fetching on their own from GetData. Sibling components,
+// e.g. author cards of the prototype, should share a Loader instead: it coalesces their requests too
+// and also batches them into one call, which a memo can not do.
+type Memo struct {
+	mutex sync.Mutex
+	calls map[interface{}]*memoCall
+}
+
+// memoCall is a fetch of a key, in flight or done.
+type memoCall struct {
+	done  chan struct{} // closed once value and err are set
+	value interface{}
+	err   error
+}
+
+func NewMemo() *Memo {
+	return &Memo{calls: make(map[interface{}]*memoCall)}
+}
+
+type memoKey struct{}
+
+// WithMemo returns a copy of ctx carrying a new memo for the render.
+func WithMemo(ctx context.Context) context.Context {
+	return context.WithValue(ctx, memoKey{}, NewMemo())
+}
+
+// MemoFrom returns the memo of the render ctx belongs to, or nil.
+func MemoFrom(ctx context.Context) *Memo {
+	memo, _ := ctx.Value(memoKey{}).(*Memo)
+	return memo
+}
+
+// Memo returns the memo of the render, or nil if the render context has none.
+func (c RenderContext) Memo() *Memo {
+	return MemoFrom(c.Ctx())
+}
+
+// fetch sets the result of the call and releases its waiters. If fetch panics,
+// waiters get an error and the panic goes on. The error of a cancelled ctx is not memoized.
+func (m *Memo) fetch(ctx context.Context, key interface{}, call *memoCall, fetch func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("fetch of %v panicked: %v", key, r)
+			close(call.done)
+			panic(r)
+		}
+	}()
+	call.value, call.err = fetch(ctx)
+	if isContextError(call.err) {
+		m.mutex.Lock()
+		delete(m.calls, key)
+		m.mutex.Unlock()
+	}
+	close(call.done)
+}
+
+// Do returns the value of the key, calling fetch only if no component of the render has asked for it yet.
+// Callers asking for a key being fetched wait for the result, or return the error of ctx once it is done.
+// If the fetch was cancelled with the context of the caller it was started by, callers fetch the key again.
+// A nil memo calls fetch every time.
+func (m *Memo) Do(ctx context.Context, key interface{}, fetch func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if m == nil {
+		return fetch(ctx)
+	}
+	m.mutex.Lock()
+	call, ok := m.calls[key]
+	if !ok {
+		call = &memoCall{done: make(chan struct{})}
+		m.calls[key] = call
+	}
+	m.mutex.Unlock()
+
+	if !ok {
+		m.fetch(ctx, key, call, fetch)
+		return call.value, call.err
+	}
+	select {
+	case <-call.done:
+		if isContextError(call.err) && ctx.Err() == nil {
+			// the fetch was cancelled with the context of the caller it was started by, not ours
+			return m.Do(ctx, key, fetch)
+		}
+		return call.value, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,193 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type memoTestKey string
+
+func TestMemoCoalesces(t *testing.T) {
+	c := NewRenderContext(context.Background(), new(bytes.Buffer))
+	var calls int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "author", nil
+	}
+	const components = 10
+	var wg sync.WaitGroup
+	values := make([]interface{}, components)
+	for i := range values {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if values[i], err = c.Memo().Do(c.Ctx(), memoTestKey("author:1"), fetch); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("identical requests should be fetched once, got %d fetches", calls)
+	}
+	for i, value := range values {
+		if value != "author" {
+			t.Errorf("component %d got %v", i, value)
+		}
+	}
+
+	// The result is shared with components asking after the fetch is done, errors included.
+	if value, _ := c.Memo().Do(c.Ctx(), memoTestKey("author:1"), fetch); value != "author" || calls != 1 {
+		t.Errorf("result should be shared during the render, got %v after %d fetches", value, calls)
+	}
+	failed := errors.New("failed")
+	for i := 0; i < 2; i++ {
+		if _, err := c.Memo().Do(c.Ctx(), memoTestKey("author:2"), func(ctx context.Context) (interface{}, error) {
+			atomic.AddInt32(&calls, 1)
+			return nil, failed
+		}); err != failed {
+			t.Errorf("expected error %v, got %v", failed, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("failed requests should be fetched once, got %d fetches", calls-1)
+	}
+}
+
+func TestMemoPerRender(t *testing.T) {
+	var calls int
+	fetch := func(ctx context.Context) (interface{}, error) {
+		calls++
+		return calls, nil
+	}
+	for i := 1; i <= 2; i++ {
+		c := NewRenderContext(context.Background(), new(bytes.Buffer))
+		if value, _ := c.Memo().Do(c.Ctx(), memoTestKey("key"), fetch); value != i {
+			t.Errorf("render %d: expected %d, got %v", i, i, value)
+		}
+	}
+	var memo *Memo // e.g. MemoFrom of a context without a memo
+	memo.Do(context.Background(), memoTestKey("key"), fetch)
+	memo.Do(context.Background(), memoTestKey("key"), fetch)
+	if calls != 4 {
+		t.Errorf("nil memo should not memoize, got %d fetches", calls)
+	}
+}
+
+func TestMemoWaitCancel(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	memo := MemoFrom(ctx)
+	release := make(chan struct{})
+	defer close(release)
+	go memo.Do(ctx, memoTestKey("slow"), func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	for {
+		memo.mutex.Lock()
+		_, started := memo.calls[memoTestKey("slow")]
+		memo.mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waiting, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := memo.Do(waiting, memoTestKey("slow"), nil); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestMemoFetchPanic(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	memo := MemoFrom(ctx)
+	release := make(chan struct{})
+	fetched := make(chan interface{})
+	go func() {
+		defer func() {
+			fetched <- recover()
+		}()
+		memo.Do(ctx, memoTestKey("panic"), func(ctx context.Context) (interface{}, error) {
+			<-release
+			panic("boom")
+		})
+	}()
+	for {
+		memo.mutex.Lock()
+		_, started := memo.calls[memoTestKey("panic")]
+		memo.mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	waited := make(chan error)
+	go func() {
+		_, err := memo.Do(ctx, memoTestKey("panic"), nil)
+		waited <- err
+	}()
+	close(release)
+	if r := <-fetched; r != "boom" {
+		t.Errorf("the panic of fetch should go on, got: %v", r)
+	}
+	if err := <-waited; err == nil || !strings.Contains(err.Error(), "panicked: boom") {
+		t.Errorf("expected the waiter to get the error of the panic, got: %v", err)
+	}
+}
+
+func TestMemoFetchCancel(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	memo := MemoFrom(ctx)
+	fetching, cancel := context.WithCancel(ctx)
+	fetched := make(chan error)
+	go func() {
+		_, err := memo.Do(fetching, memoTestKey("author"), func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+		fetched <- err
+	}()
+	for {
+		memo.mutex.Lock()
+		_, started := memo.calls[memoTestKey("author")]
+		memo.mutex.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	var calls int32
+	fetch := func(ctx context.Context) (interface{}, error) {
+		atomic.AddInt32(&calls, 1)
+		return "author", nil
+	}
+	waited := make(chan interface{})
+	go func() {
+		value, err := memo.Do(ctx, memoTestKey("author"), fetch)
+		if err != nil {
+			t.Error(err)
+		}
+		waited <- value
+	}()
+	cancel()
+	if err := <-fetched; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	if value := <-waited; value != "author" {
+		t.Errorf("a waiter with a live context should fetch again, got: %v", value)
+	}
+	if value, _ := memo.Do(ctx, memoTestKey("author"), fetch); value != "author" || calls != 1 {
+		t.Errorf("the fetch of the waiter should be memoized, got %v after %d fetches", value, calls)
+	}
+}
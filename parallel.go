@@ -0,0 +1,75 @@
+package templates
+
+import (
+	"bytes"
+	"sync"
+)
+
+// bufferPool holds buffers sibling components are rendered into by RenderParallel.
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// rendered is the output of one of the renders of RenderParallel.
+type rendered struct {
+	output *bytes.Buffer
+	err    error
+}
+
+// RenderParallel renders independent siblings, e.g. CPU heavy components like large tables, concurrently.
+// Each render writes to its own pooled buffer; buffers are written to the render context in document order
+// as soon as all the preceding ones are written. It returns the first error in document order.
+func RenderParallel(c RenderContext, renders ...RenderFunc) error {
+	if len(renders) < 2 {
+		for _, render := range renders {
+			if err := render(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	results := make([]chan rendered, len(renders))
+	for i, render := range renders {
+		results[i] = make(chan rendered, 1) // buffered so that renders do not leak when we return early
+		go func(render RenderFunc, result chan<- rendered) {
+			output := bufferPool.Get().(*bytes.Buffer)
+			rc := c
+			rc.Writer = output
+			result <- rendered{output: output, err: render(rc)}
+		}(render, results[i])
+	}
+	for i, result := range results {
+		var r rendered
+		select {
+		case r = <-result:
+		default:
+			c.flushAtComponent()
+			r = <-result
+		}
+		err := r.err
+		if err == nil {
+			_, err = c.Writer.Write(r.output.Bytes())
+		}
+		releaseBuffer(r.output)
+		if err != nil {
+			go releaseBuffers(results[i+1:])
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseBuffer returns the buffer to bufferPool.
+func releaseBuffer(output *bytes.Buffer) {
+	output.Reset()
+	bufferPool.Put(output)
+}
+
+// releaseBuffers returns buffers of the renders RenderParallel did not write to bufferPool once they finish.
+func releaseBuffers(results []chan rendered) {
+	for _, result := range results {
+		releaseBuffer((<-result).output)
+	}
+}
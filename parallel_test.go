@@ -0,0 +1,52 @@
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestRenderParallel(t *testing.T) {
+	var renders []RenderFunc
+	for i := 0; i < 5; i++ {
+		i := i
+		renders = append(renders, func(c RenderContext) error {
+			time.Sleep(time.Duration(5-i) * time.Millisecond) // The last finishes first.
+			_, err := c.WriteString("[" + strconv.Itoa(i) + "]")
+			return err
+		})
+	}
+	writer := new(bytes.Buffer)
+	if err := RenderParallel(RenderContext{Writer: writer}, renders...); err != nil {
+		t.Fatal(err)
+	}
+	if s := writer.String(); s != "[0][1][2][3][4]" {
+		t.Errorf("output should be in document order, got: %s", s)
+	}
+}
+
+func TestRenderParallelError(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	writer := new(bytes.Buffer)
+	err := RenderParallel(RenderContext{Writer: writer},
+		func(c RenderContext) error {
+			_, err := c.WriteString("ok")
+			return err
+		},
+		func(c RenderContext) error {
+			time.Sleep(time.Millisecond)
+			return first
+		},
+		func(c RenderContext) error {
+			return second
+		},
+	)
+	if err != first {
+		t.Errorf("expected the first error in document order, got: %v", err)
+	}
+	if writer.String() != "ok" {
+		t.Errorf("output preceding the error should be written, got: %q", writer.String())
+	}
+}
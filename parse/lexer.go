@@ -39,6 +39,7 @@ const (
 	itemCode                         // @code { any GO code inside }
 	itemComplex                      // complex constant (1+2i); imaginary is just a number
 	itemColonEquals                  // colon-equals (':=') introducing a declaration
+	itemAssign                       // equals ('=') introducing a default value or a named argument
 	itemEOF
 	itemEndOfLine
 	itemField      // alphanumeric identifier starting with '.'
@@ -60,6 +61,7 @@ const (
 	itemImport     // Defines Go imports
 	itemParams     // Defines input params for the template
 	itemInclude    // Include file
+	itemCache      // Caches rendered HTML of a block
 	itemCloseDirective // Directive finished
 	// Keywords appear after all the rest.
 	itemKeyword  // used only to delimit the keywords
@@ -72,6 +74,8 @@ const (
 	itemRange    // range keyword
 	itemTemplate // template keyword
 	itemWith     // with keyword
+	itemBlock    // block keyword
+	itemEndBlock // endblock keyword
 )
 
 const (
@@ -86,15 +90,22 @@ var key = map[string]itemType{
 	"else":     itemElse,
 	"end":      itemEnd,
 	"if":       itemIf,
-	"include": itemTemplate,
 	"with":     itemWith,
 }
 
+// actionKeys are keywords only as the first item of an action, elsewhere they are identifiers,
+// e.g. a param named block.
+var actionKeys = map[string]itemType{
+	"block":    itemBlock,
+	"endblock": itemEndBlock,
+}
+
 var directives = map[string]itemType {
 	"extends": itemExtends,
 	"import": itemImport,
 	"params": itemParams,
 	"include": itemInclude,
+	"cache": itemCache,
 }
 
 const eof = -1
@@ -122,11 +133,12 @@ type lexer struct {
 	lastPos    Pos       // position of most recent item returned by nextItem
 	items      chan item // channel of scanned items
 	parenDepth int       // nesting depth of ( ) exprs
+	actionStart Pos      // position of the first item of the current action
 
 	hasExtends bool
 }
 
-func (l *lexer) afterPosHasPrefix(s string) string {
+func (l *lexer) afterPosHasPrefix(s string) bool {
 	return strings.HasPrefix(l.input[l.pos:], s)
 }
 
@@ -269,16 +281,110 @@ func lexAction(l *lexer) stateFn {
 	if r != ' ' {
 		return l.errorf("Mandatory space is expected after {{.")
 	}
-	r = l.next()
-	if !isAlphaNumeric(r) {
+	l.ignore()
+	if !isAlphaNumeric(l.peek()) {
 		return l.errorf("First item of action should be action identifier")
 	}
 	l.parenDepth = 0
+	l.actionStart = l.pos
+	l.inside = lexInsideAction
 	return lexInsideAction
 }
 
+// lexInsideAction scans the elements inside action delimiters.
 func lexInsideAction(l *lexer) stateFn {
-	return nil
+	if l.afterPosHasPrefix(l.rightDelim) {
+		if l.parenDepth > 0 {
+			return l.errorf("unclosed left paren")
+		}
+		l.pos += Pos(len(l.rightDelim))
+		l.emit(itemRightDelim)
+		return lexText
+	}
+	switch r := l.next(); {
+	case r == eof:
+		return l.errorf("unclosed action")
+	case isWhitepace(r):
+		for isWhitepace(l.peek()) {
+			l.next()
+		}
+		l.ignore()
+	case r == '"':
+		l.ignore()
+		return lexQuote
+	case r == '.':
+		return lexField
+	case r == '-' || ('0' <= r && r <= '9'):
+		l.backup()
+		return lexNumber
+	case isAlphaNumeric(r):
+		l.backup()
+		return lexIdentifier
+	case r == '(':
+		l.emit(itemLeftParen)
+		l.parenDepth++
+	case r == ')':
+		l.emit(itemRightParen)
+		l.parenDepth--
+		if l.parenDepth < 0 {
+			return l.errorf("unexpected right paren %#U", r)
+		}
+	case r == ',':
+		l.emit(itemChar)
+	case r == '=':
+		l.emit(itemAssign)
+	default:
+		return l.errorf("unrecognized character in action: %#U", r)
+	}
+	return lexInsideAction
+}
+
+// lexField scans a field access: .Alphanumeric.
+// The '.' has been scanned.
+func lexField(l *lexer) stateFn {
+	if r := l.peek(); !isAlphaNumeric(r) || unicode.IsDigit(r) {
+		return l.errorf("bad field name: %q", l.input[l.start:l.pos])
+	}
+	for isAlphaNumeric(l.peek()) {
+		l.next()
+	}
+	l.emit(itemField)
+	return l.inside
+}
+
+// lexNumber scans a decimal, octal, hex or float number. This isn't
+// a perfect number scanner but when it's wrong the input is invalid
+// and the parser (via strconv) will notice.
+func lexNumber(l *lexer) stateFn {
+	if !l.scanNumber() {
+		return l.errorf("bad number syntax: %q", l.input[l.start:l.pos])
+	}
+	l.emit(itemNumber)
+	return l.inside
+}
+
+func (l *lexer) scanNumber() bool {
+	// Optional leading sign.
+	l.accept("+-")
+	// Is it hex?
+	digits := "0123456789"
+	if l.accept("0") && l.accept("xX") {
+		digits = "0123456789abcdefABCDEF"
+	}
+	l.acceptRun(digits)
+	if l.accept(".") {
+		l.acceptRun(digits)
+	}
+	if l.accept("eE") {
+		l.accept("+-")
+		l.acceptRun("0123456789")
+	}
+	// Next thing mustn't be alphanumeric.
+	if isAlphaNumeric(l.peek()) {
+		l.next()
+		return false
+	}
+	return true
 }
 
 // lexComment scans a comment. The left comment marker is known to be present.
@@ -349,11 +455,24 @@ func lexInsideDirective(l *lexer) stateFn {
 		l.next()
 		l.ignore()
 		return lexQuote
+	} else if r == '-' || ('0' <= r && r <= '9') {
+		return lexNumber
 	} else if isAlphaNumeric(r) {
 		return lexIdentifier
-	} else if isEndOfLine(r) { // TODO: How do we handle \r\n and multiple empty lines?
+	} else if strings.ContainsRune("*[].,", r) { // Parts of Go types and separators.
 		l.next()
+		l.emit(itemChar)
+		return lexInsideDirective
+	} else if r == '=' {
+		l.next()
+		l.emit(itemAssign)
+		return lexInsideDirective
+	} else if isEndOfLine(r) { // "\r\n" is emitted as a single end of line.
+		if l.next() == '\r' && l.peek() == '\n' {
+			l.next()
+		}
 		l.emit(itemEndOfLine)
+		return lexInsideDirective
 	} else if r == ')' {
 		l.next()
 		l.emit(itemCloseDirective)
@@ -380,6 +499,8 @@ func lexIdentifier(l *lexer) stateFn {
 			switch {
 			case key[word] > itemKeyword:
 				l.emit(key[word])
+			case l.start == l.actionStart && actionKeys[word] > itemKeyword:
+				l.emit(actionKeys[word])
 			//case word[0] == '.':
 			//g	l.emit(itemField)
 			//case word == "true", word == "false":
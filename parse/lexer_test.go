@@ -17,6 +17,7 @@ var itemName = map[itemType]string{
 	itemCharConstant: "charconst",
 	itemComplex:      "complex",
 	itemColonEquals:  ":=",
+	itemAssign:       "=",
 	itemEOF:          "EOF",
 	itemField:        "field",
 	itemIdentifier:   "identifier",
@@ -32,6 +33,7 @@ var itemName = map[itemType]string{
 	itemVariable:     "variable",
 
 	itemInclude: "@include",
+	itemCache: "@cache",
 	itemOpenDirective: "(",
 	itemCloseDirective: ")",
 
@@ -45,6 +47,8 @@ var itemName = map[itemType]string{
 	itemRange:    "range",
 	itemTemplate: "template",
 	itemWith:     "with",
+	itemBlock:    "block",
+	itemEndBlock: "endblock",
 
 	itemExtends: "@extends",
 }
@@ -192,6 +196,145 @@ var lexTests = []lexTest{
 		{itemText, 0, " and text"},
 		tEOF,
 	}},
+	{"@cache with ttl", `@cache(menu, ttl="1h")`, []item{
+		{itemCache, 0, "cache"},
+		{itemOpenDirective, 0, "("},
+		{itemIdentifier, 0, "menu"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "ttl"},
+		{itemAssign, 0, "="},
+		{itemString, 0, "1h"},
+		{itemCloseDirective, 0, ")"},
+		tEOF,
+	}},
+	{"@include with args", `@include("include2.html", p11=p1, p12=author.Name, p13=5)`, []item{
+		{itemInclude, 0, "include"},
+		{itemOpenDirective, 0, "("},
+		{itemString, 0, "include2.html"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "p11"},
+		{itemAssign, 0, "="},
+		{itemIdentifier, 0, "p1"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "p12"},
+		{itemAssign, 0, "="},
+		{itemIdentifier, 0, "author"},
+		{itemChar, 0, "."},
+		{itemIdentifier, 0, "Name"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "p13"},
+		{itemAssign, 0, "="},
+		{itemNumber, 0, "5"},
+		{itemCloseDirective, 0, ")"},
+		tEOF,
+	}},
+	{"action", `{{ name }}`, []item{
+		tLeft,
+		{itemIdentifier, 0, "name"},
+		tRight,
+		tEOF,
+	}},
+	{"field", `<b>{{ author.Name }}</b>`, []item{
+		{itemText, 0, "<b>"},
+		tLeft,
+		{itemIdentifier, 0, "author"},
+		{itemField, 0, ".Name"},
+		tRight,
+		{itemText, 0, "</b>"},
+		tEOF,
+	}},
+	{"call", `{{ _("Hello", -1, 2.5) }}`, []item{
+		tLeft,
+		{itemIdentifier, 0, "_"},
+		tLpar,
+		{itemString, 0, "Hello"},
+		{itemChar, 0, ","},
+		{itemNumber, 0, "-1"},
+		{itemChar, 0, ","},
+		{itemNumber, 0, "2.5"},
+		tRpar,
+		tRight,
+		tEOF,
+	}},
+	{"block", `{{ block body }}text{{ endblock }}`, []item{
+		tLeft,
+		{itemBlock, 0, "block"},
+		{itemIdentifier, 0, "body"},
+		tRight,
+		{itemText, 0, "text"},
+		tLeft,
+		{itemEndBlock, 0, "endblock"},
+		tRight,
+		tEOF,
+	}},
+	{"block as identifier", `{{ block block }}{{ f(block) }}{{ endblock }}`, []item{
+		tLeft,
+		{itemBlock, 0, "block"},
+		{itemIdentifier, 0, "block"},
+		tRight,
+		tLeft,
+		{itemIdentifier, 0, "f"},
+		tLpar,
+		{itemIdentifier, 0, "block"},
+		tRpar,
+		tRight,
+		tLeft,
+		{itemEndBlock, 0, "endblock"},
+		tRight,
+		tEOF,
+	}},
+	{"@params on multiple lines", "@params(\n\tp1 string\r\n)", []item{
+		{itemParams, 0, "params"},
+		{itemOpenDirective, 0, "("},
+		{itemEndOfLine, 0, "\n"},
+		{itemIdentifier, 0, "p1"},
+		{itemIdentifier, 0, "string"},
+		{itemEndOfLine, 0, "\r\n"},
+		{itemCloseDirective, 0, ")"},
+		tEOF,
+	}},
+	{"@params with types and defaults", `@params(p1 *m.Author required, p2 map[string][]int, p3 int = -1)`, []item{
+		{itemParams, 0, "params"},
+		{itemOpenDirective, 0, "("},
+		{itemIdentifier, 0, "p1"},
+		{itemChar, 0, "*"},
+		{itemIdentifier, 0, "m"},
+		{itemChar, 0, "."},
+		{itemIdentifier, 0, "Author"},
+		{itemIdentifier, 0, "required"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "p2"},
+		{itemIdentifier, 0, "map"},
+		{itemChar, 0, "["},
+		{itemIdentifier, 0, "string"},
+		{itemChar, 0, "]"},
+		{itemChar, 0, "["},
+		{itemChar, 0, "]"},
+		{itemIdentifier, 0, "int"},
+		{itemChar, 0, ","},
+		{itemIdentifier, 0, "p3"},
+		{itemIdentifier, 0, "int"},
+		{itemAssign, 0, "="},
+		{itemNumber, 0, "-1"},
+		{itemCloseDirective, 0, ")"},
+		tEOF,
+	}},
+	{"action without space", `{{name}}`, []item{
+		tLeft,
+		{itemError, 0, "Mandatory space is expected after {{."},
+	}},
+	{"unclosed action", `{{ name`, []item{
+		tLeft,
+		{itemIdentifier, 0, "name"},
+		{itemError, 0, "unclosed action"},
+	}},
+	{"unclosed paren in action", `{{ _("a" }}`, []item{
+		tLeft,
+		{itemIdentifier, 0, "_"},
+		tLpar,
+		{itemString, 0, "a"},
+		{itemError, 0, "unclosed left paren"},
+	}},
 	/*
 	{"punctuation", "{{,@% }}", []item{
 		tLeft,
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var textFormat = "%s" // Changed to "%q" in tests for better error messages.
@@ -66,6 +67,16 @@ const (
 	NodeTemplate                   // A template invocation action.
 	NodeVariable                   // A $ variable.
 	NodeWith                       // A with action.
+	NodeBlock                      // A block definition.
+	NodeCall                       // A function call.
+	NodeExtends                    // An @extends directive.
+	NodeImport                     // A single import of an @import directive.
+	NodeParams                     // An @params directive.
+	NodeParam                      // A single parameter of an @params directive.
+	NodeInclude                    // An @include directive.
+	NodeArg                        // A named argument of an @include directive or a call.
+	NodeCache                      // An @cache directive.
+	nodeEndBlock                   // An endblock action. Not added to tree.
 )
 
 // Nodes.
@@ -200,21 +211,21 @@ func (p *PipeNode) Copy() Node {
 
 // ActionNode holds an action (something bounded by delimiters).
 // Control actions have their own nodes; ActionNode represents simple
-// ones such as field evaluations and parenthesized pipelines.
+// ones such as parameter evaluations and function calls.
 type ActionNode struct {
 	NodeType
 	Pos
 	tr   *Tree
-	Line int       // The line number in the input (deprecated; kept for compatibility)
-	Pipe *PipeNode // The pipeline in the action.
+	Line int  // The line number in the input (deprecated; kept for compatibility)
+	Expr Node // The expression to output.
 }
 
-func (t *Tree) newAction(pos Pos, line int, pipe *PipeNode) *ActionNode {
-	return &ActionNode{tr: t, NodeType: NodeAction, Pos: pos, Line: line, Pipe: pipe}
+func (t *Tree) newAction(pos Pos, line int, expr Node) *ActionNode {
+	return &ActionNode{tr: t, NodeType: NodeAction, Pos: pos, Line: line, Expr: expr}
 }
 
 func (a *ActionNode) String() string {
-	return fmt.Sprintf("{{%s}}", a.Pipe)
+	return fmt.Sprintf("{{ %s }}", a.Expr)
 
 }
 
@@ -223,7 +234,7 @@ func (a *ActionNode) tree() *Tree {
 }
 
 func (a *ActionNode) Copy() Node {
-	return a.tr.newAction(a.Pos, a.Line, a.Pipe.CopyPipe())
+	return a.tr.newAction(a.Pos, a.Line, a.Expr.Copy())
 
 }
 
@@ -829,3 +840,411 @@ func (t *TemplateNode) tree() *Tree {
 func (t *TemplateNode) Copy() Node {
 	return t.tr.newTemplate(t.Pos, t.Line, t.Name, t.Pipe.CopyPipe())
 }
+
+// BlockNode represents a {{ block }} definition.
+type BlockNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Line int       // The line number in the input (deprecated; kept for compatibility)
+	Name string    // The name of the block.
+	List *ListNode // The default content of the block.
+}
+
+func (t *Tree) newBlock(pos Pos, line int, name string, list *ListNode) *BlockNode {
+	return &BlockNode{tr: t, NodeType: NodeBlock, Pos: pos, Line: line, Name: name, List: list}
+}
+
+func (b *BlockNode) String() string {
+	return fmt.Sprintf("{{ block %s }}%s{{ endblock }}", b.Name, b.List)
+}
+
+func (b *BlockNode) tree() *Tree {
+	return b.tr
+}
+
+func (b *BlockNode) Copy() Node {
+	return b.tr.newBlock(b.Pos, b.Line, b.Name, b.List.CopyList())
+}
+
+// endBlockNode represents an {{ endblock }} action.
+// It does not appear in the final parse tree.
+type endBlockNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Name string // Optional name of the block being closed.
+}
+
+func (t *Tree) newEndBlock(pos Pos, name string) *endBlockNode {
+	return &endBlockNode{tr: t, NodeType: nodeEndBlock, Pos: pos, Name: name}
+}
+
+func (e *endBlockNode) String() string {
+	if e.Name != "" {
+		return fmt.Sprintf("{{ endblock %s }}", e.Name)
+	}
+	return "{{ endblock }}"
+}
+
+func (e *endBlockNode) tree() *Tree {
+	return e.tr
+}
+
+func (e *endBlockNode) Copy() Node {
+	return e.tr.newEndBlock(e.Pos, e.Name)
+}
+
+// CallNode holds a function call such as _("text").
+type CallNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Func  *IdentifierNode // The called function.
+	Args  []Node          // Positional arguments in lexical order.
+	Named []*ArgNode      // Named arguments following the positional ones, e.g. name=author.Name.
+}
+
+func (t *Tree) newCall(pos Pos, fn *IdentifierNode) *CallNode {
+	return &CallNode{tr: t, NodeType: NodeCall, Pos: pos, Func: fn}
+}
+
+func (c *CallNode) append(arg Node) {
+	c.Args = append(c.Args, arg)
+}
+
+// Arg returns the named argument with the given name, or nil.
+func (c *CallNode) Arg(name string) *ArgNode {
+	for _, arg := range c.Named {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func (c *CallNode) String() string {
+	args := make([]string, 0, len(c.Args)+len(c.Named))
+	for _, arg := range c.Args {
+		args = append(args, arg.String())
+	}
+	for _, arg := range c.Named {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", c.Func, strings.Join(args, ", "))
+}
+
+func (c *CallNode) tree() *Tree {
+	return c.tr
+}
+
+func (c *CallNode) Copy() Node {
+	n := c.tr.newCall(c.Pos, c.Func.Copy().(*IdentifierNode))
+	for _, arg := range c.Args {
+		n.append(arg.Copy())
+	}
+	for _, arg := range c.Named {
+		n.Named = append(n.Named, arg.Copy().(*ArgNode))
+	}
+	return n
+}
+
+// ExtendsNode represents an @extends directive.
+type ExtendsNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Path string // Path to the parent template (unquoted).
+}
+
+func (t *Tree) newExtends(pos Pos, path string) *ExtendsNode {
+	return &ExtendsNode{tr: t, NodeType: NodeExtends, Pos: pos, Path: path}
+}
+
+func (e *ExtendsNode) String() string {
+	return fmt.Sprintf("@extends(%q)", e.Path)
+}
+
+func (e *ExtendsNode) tree() *Tree {
+	return e.tr
+}
+
+func (e *ExtendsNode) Copy() Node {
+	return e.tr.newExtends(e.Pos, e.Path)
+}
+
+// ImportNode represents a single Go import of an @import directive.
+type ImportNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Name string // Optional local name of the imported package.
+	Path string // Import path (unquoted).
+}
+
+func (t *Tree) newImport(pos Pos, name, path string) *ImportNode {
+	return &ImportNode{tr: t, NodeType: NodeImport, Pos: pos, Name: name, Path: path}
+}
+
+func (i *ImportNode) String() string {
+	if i.Name != "" {
+		return fmt.Sprintf("%s %q", i.Name, i.Path)
+	}
+	return fmt.Sprintf("%q", i.Path)
+}
+
+func (i *ImportNode) tree() *Tree {
+	return i.tr
+}
+
+func (i *ImportNode) Copy() Node {
+	return i.tr.newImport(i.Pos, i.Name, i.Path)
+}
+
+// ParamsNode represents an @params directive.
+type ParamsNode struct {
+	NodeType
+	Pos
+	tr     *Tree
+	Params []*ParamNode // The parameters in lexical order.
+}
+
+func (t *Tree) newParams(pos Pos) *ParamsNode {
+	return &ParamsNode{tr: t, NodeType: NodeParams, Pos: pos}
+}
+
+func (p *ParamsNode) append(param *ParamNode) {
+	p.Params = append(p.Params, param)
+}
+
+// Param returns the parameter with the given name or nil if there is no such parameter.
+func (p *ParamsNode) Param(name string) *ParamNode {
+	if p == nil {
+		return nil
+	}
+	for _, param := range p.Params {
+		if param.Name == name {
+			return param
+		}
+	}
+	return nil
+}
+
+func (p *ParamsNode) String() string {
+	params := make([]string, len(p.Params))
+	for i, param := range p.Params {
+		params[i] = param.String()
+	}
+	return fmt.Sprintf("@params(%s)", strings.Join(params, "\n"))
+}
+
+func (p *ParamsNode) tree() *Tree {
+	return p.tr
+}
+
+func (p *ParamsNode) Copy() Node {
+	n := p.tr.newParams(p.Pos)
+	for _, param := range p.Params {
+		n.append(param.Copy().(*ParamNode))
+	}
+	return n
+}
+
+// ParamNode holds a single parameter of an @params directive.
+type ParamNode struct {
+	NodeType
+	Pos
+	tr       *Tree
+	Name     string // The name of the parameter.
+	GoType   string // Go type of the parameter, e.g. "[]*models.Author".
+	Required bool   // Whether the parameter is marked as required.
+	Default  Node   // Default value of an optional parameter: string, number, bool or nil; nil if not set.
+}
+
+func (t *Tree) newParam(pos Pos, name, goType string, required bool, defaultValue Node) *ParamNode {
+	return &ParamNode{tr: t, NodeType: NodeParam, Pos: pos, Name: name, GoType: goType, Required: required, Default: defaultValue}
+}
+
+func (p *ParamNode) String() string {
+	s := p.Name + " " + p.GoType
+	if p.Required {
+		s += " required"
+	}
+	if p.Default != nil {
+		s += " = " + p.Default.String()
+	}
+	return s
+}
+
+func (p *ParamNode) tree() *Tree {
+	return p.tr
+}
+
+func (p *ParamNode) Copy() Node {
+	var defaultValue Node
+	if p.Default != nil {
+		defaultValue = p.Default.Copy()
+	}
+	return p.tr.newParam(p.Pos, p.Name, p.GoType, p.Required, defaultValue)
+}
+
+// IncludeNode represents an @include directive rendering another template in place.
+type IncludeNode struct {
+	NodeType
+	Pos
+	tr   *Tree
+	Path string     // Path to the included template (unquoted).
+	Args []*ArgNode // Named arguments passed to params of the included template.
+}
+
+func (t *Tree) newInclude(pos Pos, path string) *IncludeNode {
+	return &IncludeNode{tr: t, NodeType: NodeInclude, Pos: pos, Path: path}
+}
+
+// Arg returns the argument passed to the param with the given name, or nil.
+func (i *IncludeNode) Arg(name string) *ArgNode {
+	for _, arg := range i.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func (i *IncludeNode) String() string {
+	s := fmt.Sprintf("@include(%q", i.Path)
+	for _, arg := range i.Args {
+		s += ", " + arg.String()
+	}
+	return s + ")"
+}
+
+func (i *IncludeNode) tree() *Tree {
+	return i.tr
+}
+
+func (i *IncludeNode) Copy() Node {
+	n := i.tr.newInclude(i.Pos, i.Path)
+	for _, arg := range i.Args {
+		n.Args = append(n.Args, arg.Copy().(*ArgNode))
+	}
+	return n
+}
+
+// ArgNode represents a named argument of an @include directive, e.g. p11=p1, or of a call.
+type ArgNode struct {
+	NodeType
+	Pos
+	tr    *Tree
+	Name  string // Name of the param of the included template or of the call argument.
+	Value Node   // A literal, identifier or field chain; any expression in calls.
+}
+
+func (t *Tree) newArg(pos Pos, name string, value Node) *ArgNode {
+	return &ArgNode{tr: t, NodeType: NodeArg, Pos: pos, Name: name, Value: value}
+}
+
+func (a *ArgNode) String() string {
+	return a.Name + "=" + a.Value.String()
+}
+
+func (a *ArgNode) tree() *Tree {
+	return a.tr
+}
+
+func (a *ArgNode) Copy() Node {
+	return a.tr.newArg(a.Pos, a.Name, a.Value.Copy())
+}
+
+// CacheNode represents an @cache directive turning on the fragment cache for a block.
+type CacheNode struct {
+	NodeType
+	Pos
+	tr    *Tree
+	Block string        // Name of the cached block.
+	TTL   time.Duration // How long the block is cached; the default of the cache if 0.
+}
+
+func (t *Tree) newCache(pos Pos, block string, ttl time.Duration) *CacheNode {
+	return &CacheNode{tr: t, NodeType: NodeCache, Pos: pos, Block: block, TTL: ttl}
+}
+
+func (c *CacheNode) String() string {
+	if c.TTL != 0 {
+		return fmt.Sprintf("@cache(%s, ttl=%q)", c.Block, c.TTL)
+	}
+	return fmt.Sprintf("@cache(%s)", c.Block)
+}
+
+func (c *CacheNode) tree() *Tree {
+	return c.tr
+}
+
+func (c *CacheNode) Copy() Node {
+	return c.tr.newCache(c.Pos, c.Block, c.TTL)
+}
+
+// Inspect traverses the node and its children in lexical order, calling f for each node.
+// Children of a node are skipped if f returns false for it.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+	switch n := node.(type) {
+	case *ListNode:
+		for _, child := range n.Nodes {
+			Inspect(child, f)
+		}
+	case *ActionNode:
+		Inspect(n.Expr, f)
+	case *PipeNode:
+		for _, cmd := range n.Cmds {
+			Inspect(cmd, f)
+		}
+	case *CommandNode:
+		for _, arg := range n.Args {
+			Inspect(arg, f)
+		}
+	case *ChainNode:
+		Inspect(n.Node, f)
+	case *IfNode:
+		inspectBranch(&n.BranchNode, f)
+	case *RangeNode:
+		inspectBranch(&n.BranchNode, f)
+	case *WithNode:
+		inspectBranch(&n.BranchNode, f)
+	case *TemplateNode:
+		if n.Pipe != nil {
+			Inspect(n.Pipe, f)
+		}
+	case *BlockNode:
+		if n.List != nil {
+			Inspect(n.List, f)
+		}
+	case *CallNode:
+		for _, arg := range n.Args {
+			Inspect(arg, f)
+		}
+		for _, arg := range n.Named {
+			Inspect(arg, f)
+		}
+	case *IncludeNode:
+		for _, arg := range n.Args {
+			Inspect(arg, f)
+		}
+	case *ArgNode:
+		Inspect(n.Value, f)
+	}
+}
+
+func inspectBranch(b *BranchNode, f func(Node) bool) {
+	if b.Pipe != nil {
+		Inspect(b.Pipe, f)
+	}
+	if b.List != nil {
+		Inspect(b.List, f)
+	}
+	if b.ElseList != nil {
+		Inspect(b.ElseList, f)
+	}
+}
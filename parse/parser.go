@@ -14,6 +14,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Tree is the representation of a single parsed template.
@@ -21,6 +22,11 @@ type Tree struct {
 	Name      string    // name of the template represented by the tree.
 	ParseName string    // name of the top-level template during parsing, for error messages.
 	Root      *ListNode // top-level root of the tree.
+	Extends   *ExtendsNode  // parent template; nil if the template does not extend another one.
+	Imports   []*ImportNode // Go imports declared with @import.
+	Params    *ParamsNode   // input parameters declared with @params; nil if not declared.
+	Blocks    []*BlockNode  // all blocks defined by the template, in lexical order.
+	Caches    []*CacheNode  // blocks cached with @cache, in lexical order.
 	text      string    // text parsed to create the template (or its parent)
 						// Parsing only; cleared after parse.
 	funcs     []map[string]interface{}
@@ -39,10 +45,25 @@ func (t *Tree) Copy() *Tree {
 		Name:      t.Name,
 		ParseName: t.ParseName,
 		Root:      t.Root.CopyList(),
+		Extends:   t.Extends,
+		Imports:   t.Imports,
+		Params:    t.Params,
+		Blocks:    t.Blocks,
+		Caches:    t.Caches,
 		text:      t.text,
 	}
 }
 
+// Block returns the block defined by the template with the given name or nil if there is no such block.
+func (t *Tree) Block(name string) *BlockNode {
+	for _, block := range t.Blocks {
+		if block.Name == name {
+			return block
+		}
+	}
+	return nil
+}
+
 // Parse returns a map from template name to parse.Tree, created by parsing the
 // templates described in the argument string. The top-level template will be
 // given the specified name. If an error is encountered, parsing stops and an
@@ -153,6 +174,15 @@ func (t *Tree) ErrorContext(n Node) (location, context string) {
 	return fmt.Sprintf("%s:%d:%d", tree.ParseName, lineNum, byteNum), context
 }
 
+// LineNumber returns the line of the node in the input text, starting at 1.
+func (t *Tree) LineNumber(n Node) int {
+	tree := n.tree()
+	if tree == nil {
+		tree = t
+	}
+	return 1 + strings.Count(tree.text[:n.Position()], "\n")
+}
+
 // errorf formats the error and terminates processing.
 func (t *Tree) errorf(format string, args ...interface{}) {
 	t.Root = nil
@@ -185,6 +215,9 @@ func (t *Tree) expectOneOf(expected1, expected2 itemType, context string) item {
 
 // unexpected complains about the token and terminates processing.
 func (t *Tree) unexpected(token item, context string) {
+	if token.typ == itemError {
+		t.errorf("%s", token.val)
+	}
 	t.errorf("unexpected %s in %s", token, context)
 }
 
@@ -251,7 +284,9 @@ func IsEmptyTree(n Node) bool {
 		case nil:
 		return true
 		case *ActionNode:
+		case *BlockNode:
 		case *IfNode:
+		case *IncludeNode:
 		case *ListNode:
 		for _, node := range n.Nodes {
 			if !IsEmptyTree(node) {
@@ -270,13 +305,27 @@ func IsEmptyTree(n Node) bool {
 	return false
 }
 
+// Cache returns the @cache directive of the block with the given name or nil if the block is not cached.
+func (t *Tree) Cache(block string) *CacheNode {
+	for _, cache := range t.Caches {
+		if cache.Block == block {
+			return cache
+		}
+	}
+	return nil
+}
+
 // parse is the top-level parser for a template, essentially the same
 // as itemList except it also parses {{define}} actions.
 // It runs to EOF.
 func (t *Tree) parse(treeSet map[string]*Tree) (next Node) {
 	t.Root = t.newList(t.peek().pos)
 	for t.peek().typ != itemEOF {
-		if t.peek().typ == itemLeftDelim {
+		switch t.peek().typ {
+		case itemExtends, itemImport, itemParams, itemCache:
+			t.directive()
+			continue
+		case itemLeftDelim:
 			delim := t.next()
 			if t.nextNonSpace().typ == itemDefine {
 				newT := New("definition") // name will be updated once we know it.
@@ -289,14 +338,254 @@ func (t *Tree) parse(treeSet map[string]*Tree) (next Node) {
 			t.backup2(delim)
 		}
 		n := t.textOrAction()
-		if n.Type() == nodeEnd {
+		if n.Type() == nodeEnd || n.Type() == nodeEndBlock {
 			t.errorf("unexpected %s", n)
 		}
+		if t.Extends != nil && n.Type() != NodeBlock && !IsEmptyTree(n) {
+			// Only blocks of an extending template are rendered, by the parent.
+			t.errorf("unexpected %s outside of blocks in template extending %q", n, t.Extends.Path)
+		}
 		t.Root.append(n)
 	}
 	return nil
 }
 
+// directive:
+//	@extends | @import | @params | @cache
+func (t *Tree) directive() {
+	switch token := t.next(); token.typ {
+	case itemExtends:
+		t.extendsDirective(token)
+	case itemImport:
+		t.importDirective(token)
+	case itemParams:
+		t.paramsDirective(token)
+	case itemCache:
+		t.cacheDirective(token)
+	default:
+		t.unexpected(token, "directive")
+	}
+}
+
+// Extends:
+//	@extends("path")
+// The directive keyword is past.
+func (t *Tree) extendsDirective(token item) {
+	const context = "@extends"
+	if t.Extends != nil {
+		t.errorf("duplicate @extends")
+	}
+	if !IsEmptyTree(t.Root) {
+		t.errorf("@extends must precede template content")
+	}
+	t.expect(itemOpenDirective, context)
+	path := t.expect(itemString, context)
+	t.expect(itemCloseDirective, context)
+	t.Extends = t.newExtends(token.pos, path.val)
+}
+
+// Import:
+//	@import([name] "path" ...)
+// Imports are separated by new lines. The directive keyword is past.
+func (t *Tree) importDirective(token item) {
+	const context = "@import"
+	t.expect(itemOpenDirective, context)
+	for {
+		switch token := t.nextNonSpace(); token.typ {
+		case itemEndOfLine:
+		case itemCloseDirective:
+			return
+		case itemIdentifier:
+			path := t.expect(itemString, context)
+			t.Imports = append(t.Imports, t.newImport(token.pos, token.val, path.val))
+		case itemString:
+			t.Imports = append(t.Imports, t.newImport(token.pos, "", token.val))
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// Params:
+//	@params(param ...)
+// Parameters are separated by new lines, commas or spaces. The directive keyword is past.
+func (t *Tree) paramsDirective(token item) {
+	const context = "@params"
+	if t.Params != nil {
+		t.errorf("duplicate @params")
+	}
+	t.Params = t.newParams(token.pos)
+	t.expect(itemOpenDirective, context)
+	for {
+		switch token := t.nextNonSpace(); {
+		case token.typ == itemEndOfLine, token.typ == itemChar && token.val == ",":
+		case token.typ == itemCloseDirective:
+			return
+		case token.typ == itemIdentifier:
+			if t.Params.Param(token.val) != nil {
+				t.errorf("duplicate param %q", token.val)
+			}
+			t.Params.append(t.param(token, context))
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// Param:
+//	name type [required | optional] ['=' default]
+// Name is past.
+func (t *Tree) param(name item, context string) *ParamNode {
+	goType := t.goType(context)
+	required := false
+	if modifier := t.peekNonSpace(); modifier.typ == itemIdentifier && (modifier.val == "required" || modifier.val == "optional") {
+		t.next()
+		required = modifier.val == "required"
+	}
+	var defaultValue Node
+	if t.peekNonSpace().typ == itemAssign {
+		t.next()
+		if required {
+			t.errorf("required param %q can not have a default value", name.val)
+		}
+		defaultValue = t.literal(context)
+	}
+	return t.newParam(name.pos, name.val, goType, required, defaultValue)
+}
+
+// Type:
+//	'*' type
+//	'[' ']' type
+//	'map' '[' type ']' type
+//	identifier ['.' identifier]
+func (t *Tree) goType(context string) string {
+	token := t.nextNonSpace()
+	switch {
+	case token.typ == itemChar && token.val == "*":
+		return "*" + t.goType(context)
+	case token.typ == itemChar && token.val == "[":
+		t.expectChar("]", context)
+		return "[]" + t.goType(context)
+	case token.typ == itemIdentifier && token.val == "map":
+		t.expectChar("[", context)
+		key := t.goType(context)
+		t.expectChar("]", context)
+		return "map[" + key + "]" + t.goType(context)
+	case token.typ == itemIdentifier:
+		if next := t.peekNonSpace(); next.typ == itemChar && next.val == "." {
+			t.next()
+			return token.val + "." + t.expect(itemIdentifier, context).val
+		}
+		return token.val
+	}
+	t.unexpected(token, context)
+	return ""
+}
+
+// Cache:
+//	@cache(block[, ttl="duration"])
+// The duration is parsed by time.ParseDuration, e.g. "90s" or "1h". The directive keyword is past.
+func (t *Tree) cacheDirective(token item) {
+	const context = "@cache"
+	pos := token.pos
+	t.expect(itemOpenDirective, context)
+	block := t.expect(itemIdentifier, context)
+	if t.Cache(block.val) != nil {
+		t.errorf("duplicate @cache of block %q", block.val)
+	}
+	var ttl time.Duration
+	for {
+		switch token := t.nextNonSpace(); {
+		case token.typ == itemCloseDirective:
+			t.Caches = append(t.Caches, t.newCache(pos, block.val, ttl))
+			return
+		case token.typ == itemChar && token.val == ",":
+			option := t.expect(itemIdentifier, context)
+			if option.val != "ttl" {
+				t.errorf("unknown %s option %q", context, option.val)
+			}
+			t.expect(itemAssign, context)
+			value := t.expect(itemString, context)
+			var err error
+			if ttl, err = time.ParseDuration(value.val); err != nil || ttl <= 0 {
+				t.errorf("bad %s ttl %q", context, value.val)
+			}
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// Include:
+//	@include("path"[, name=value ...])
+// Values are literals, params or their fields. The directive keyword is past.
+func (t *Tree) includeDirective(token item) *IncludeNode {
+	const context = "@include"
+	t.expect(itemOpenDirective, context)
+	include := t.newInclude(token.pos, t.expect(itemString, context).val)
+	for {
+		switch token := t.nextNonSpace(); {
+		case token.typ == itemCloseDirective:
+			return include
+		case token.typ == itemChar && token.val == ",":
+			name := t.expect(itemIdentifier, context)
+			if include.Arg(name.val) != nil {
+				t.errorf("duplicate argument %q in %s", name.val, context)
+			}
+			t.expect(itemAssign, context)
+			include.Args = append(include.Args, t.newArg(name.pos, name.val, t.argValue(context)))
+		default:
+			t.unexpected(token, context)
+		}
+	}
+}
+
+// argValue:
+//	literal | identifier[.Field...]
+func (t *Tree) argValue(context string) Node {
+	token := t.nextNonSpace()
+	if token.typ != itemIdentifier || token.val == "true" || token.val == "false" || token.val == "nil" {
+		t.backup()
+		return t.literal(context)
+	}
+	var node Node = NewIdentifier(token.val).SetTree(t).SetPos(token.pos)
+	if dot := t.peek(); dot.typ == itemChar && dot.val == "." {
+		chain := t.newChain(dot.pos, node)
+		for dot := t.peek(); dot.typ == itemChar && dot.val == "."; dot = t.peek() {
+			t.next()
+			chain.Add("." + t.expect(itemIdentifier, context).val)
+		}
+		node = chain
+	}
+	return node
+}
+
+// expectChar consumes the next token and guarantees it is the given char.
+func (t *Tree) expectChar(char string, context string) item {
+	token := t.nextNonSpace()
+	if token.typ != itemChar || token.val != char {
+		t.unexpected(token, context)
+	}
+	return token
+}
+
+// literal:
+//	string | number | true | false | nil
+func (t *Tree) literal(context string) Node {
+	switch token := t.nextNonSpace(); {
+	case token.typ == itemString, token.typ == itemNumber:
+		t.backup()
+		return t.primary(context)
+	case token.typ == itemIdentifier && (token.val == "true" || token.val == "false"):
+		return t.newBool(token.pos, token.val == "true")
+	case token.typ == itemIdentifier && token.val == "nil":
+		return t.newNil(token.pos)
+	default:
+		t.unexpected(token, context)
+	}
+	return nil
+}
+
 // parseDefinition parses a {{define}} ...  {{end}} template definition and
 // installs the definition in the treeSet map.  The "define" keyword has already
 // been scanned.
@@ -326,7 +615,7 @@ func (t *Tree) itemList() (list *ListNode, next Node) {
 	for t.peekNonSpace().typ != itemEOF {
 		n := t.textOrAction()
 		switch n.Type() {
-		case nodeEnd, nodeElse:
+		case nodeEnd, nodeElse, nodeEndBlock:
 			return list, n
 		}
 		list.append(n)
@@ -343,6 +632,8 @@ func (t *Tree) textOrAction() Node {
 		return t.newText(token.pos, token.val)
 	case itemLeftDelim:
 		return t.action()
+	case itemInclude:
+		return t.includeDirective(token)
 	default:
 		t.unexpected(token, "input")
 	}
@@ -356,6 +647,10 @@ func (t *Tree) textOrAction() Node {
 // First word could be a keyword such as range.
 func (t *Tree) action() (n Node) {
 	switch token := t.nextNonSpace(); token.typ {
+	case itemBlock:
+		return t.blockControl()
+	case itemEndBlock:
+		return t.endBlockControl()
 	case itemElse:
 		return t.elseControl()
 	case itemEnd:
@@ -370,8 +665,98 @@ func (t *Tree) action() (n Node) {
 		return t.withControl()
 	}
 	t.backup()
-	// Do not pop variables; they persist until "end".
-	return t.newAction(t.peek().pos, t.lex.lineNumber(), t.pipeline("command"))
+	pos, line := t.peek().pos, t.lex.lineNumber()
+	expr := t.expression("command")
+	t.expect(itemRightDelim, "command")
+	return t.newAction(pos, line, expr)
+}
+
+// expression:
+//	primary ('.' Field)*
+func (t *Tree) expression(context string) Node {
+	node := t.primary(context)
+	if t.peek().typ == itemField {
+		chain := t.newChain(t.peek().pos, node)
+		for t.peek().typ == itemField {
+			chain.Add(t.next().val)
+		}
+		node = chain
+	}
+	return node
+}
+
+// primary:
+//	identifier
+//	identifier '(' (argument (',' argument)*)? ')'
+//	literal (number, string)
+//	'(' expression ')'
+func (t *Tree) primary(context string) Node {
+	switch token := t.nextNonSpace(); token.typ {
+	case itemIdentifier:
+		ident := NewIdentifier(token.val).SetTree(t).SetPos(token.pos)
+		if t.peek().typ != itemLeftParen {
+			return ident
+		}
+		t.next()
+		call := t.newCall(token.pos, ident)
+		if t.peekNonSpace().typ == itemRightParen {
+			t.next()
+			return call
+		}
+		for {
+			t.argument(call, context)
+			switch token := t.nextNonSpace(); {
+			case token.typ == itemRightParen:
+				return call
+			case token.typ == itemChar && token.val == ",":
+			default:
+				t.unexpected(token, context)
+			}
+		}
+	case itemNumber:
+		number, err := t.newNumber(token.pos, token.val, token.typ)
+		if err != nil {
+			t.error(err)
+		}
+		return number
+	case itemString:
+		// The lexer strips the quotes.
+		quoted := `"` + token.val + `"`
+		s, err := strconv.Unquote(quoted)
+		if err != nil {
+			t.error(err)
+		}
+		return t.newString(token.pos, quoted, s)
+	case itemLeftParen:
+		expr := t.expression(context)
+		t.expect(itemRightParen, context)
+		return expr
+	default:
+		t.unexpected(token, context)
+	}
+	return nil
+}
+
+// argument:
+//	expression | identifier '=' expression
+// Named arguments follow positional ones.
+func (t *Tree) argument(call *CallNode, context string) {
+	if name := t.peekNonSpace(); name.typ == itemIdentifier {
+		t.next()
+		if t.peek().typ == itemAssign {
+			t.next()
+			if call.Arg(name.val) != nil {
+				t.errorf("duplicate argument %q in call of %s", name.val, call.Func)
+			}
+			call.Named = append(call.Named, t.newArg(name.pos, name.val, t.expression(context)))
+			return
+		}
+		t.backup2(name)
+	}
+	if len(call.Named) > 0 {
+		t.errorf("positional argument after named arguments in call of %s", call.Func)
+	}
+	call.append(t.expression(context))
 }
 
 // Pipeline:
@@ -436,6 +821,8 @@ func (t *Tree) parseControl(allowElseIf bool, context string) (pos Pos, line int
 	list, next = t.itemList()
 	switch next.Type() {
 	case nodeEnd: //done
+	case nodeEndBlock:
+		t.errorf("unexpected %s in %s", next, context)
 	case nodeElse:
 		if allowElseIf {
 			// Special case for "else if". If the "else" is followed immediately by an "if",
@@ -486,6 +873,45 @@ func (t *Tree) withControl() Node {
 	return t.newWith(t.parseControl(false, "with"))
 }
 
+// Block:
+//	{{ block name }} itemList {{ endblock [name] }}
+// Block keyword is past.
+func (t *Tree) blockControl() Node {
+	const context = "block clause"
+	name := t.expect(itemIdentifier, context)
+	t.expect(itemRightDelim, context)
+	if t.Block(name.val) != nil {
+		t.errorf("block %q redefined", name.val)
+	}
+	block := t.newBlock(name.pos, t.lex.lineNumber(), name.val, nil)
+	t.Blocks = append(t.Blocks, block)
+	var end Node
+	block.List, end = t.itemList()
+	if end.Type() != nodeEndBlock {
+		t.errorf("unexpected %s in %s", end, context)
+	}
+	if endName := end.(*endBlockNode).Name; endName != "" && endName != block.Name {
+		t.errorf("%s does not close block %q", end, block.Name)
+	}
+	return block
+}
+
+// EndBlock:
+//	{{ endblock [name] }}
+// Endblock keyword is past.
+func (t *Tree) endBlockControl() Node {
+	name := ""
+	token := t.nextNonSpace()
+	if token.typ == itemIdentifier {
+		name = token.val
+		token = t.nextNonSpace()
+	}
+	if token.typ != itemRightDelim {
+		t.unexpected(token, "endblock")
+	}
+	return t.newEndBlock(token.pos, name)
+}
+
 // End:
 //	{{end}}
 // End keyword is past.
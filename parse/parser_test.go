@@ -0,0 +1,176 @@
+package parse
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type parseTest struct {
+	name   string
+	input  string
+	ok     bool
+	result string // what the user would see in an error message.
+}
+
+const (
+	noError  = true
+	hasError = false
+)
+
+var parseTests = []parseTest{
+	{"empty", "", noError, ``},
+	{"text", "some text", noError, `some text`},
+	{"comment", "a{{/* comment */}}b", noError, `ab`},
+	{"param", "Hello, {{ name }}!", noError, `Hello, {{ name }}!`},
+	{"field chain", "{{ author.Address.City }}", noError, `{{ author.Address.City }}`},
+	{"call", `{{ _("Welcome to page") }}`, noError, `{{ _("Welcome to page") }}`},
+	{"call with args", `{{ f(1, "a", g(), x.Y) }}`, noError, `{{ f(1, "a", g(), x.Y) }}`},
+	{"call with named args", `{{ _("Hi, {name}!", name=author.Name, n=f(1)) }}`, noError, `{{ _("Hi, {name}!", name=author.Name, n=f(1)) }}`},
+	{"plural", `{{ _n("%d message", "%d messages", count) }}`, noError, `{{ _n("%d message", "%d messages", count) }}`},
+	{"block", "a{{ block body }}b{{ endblock }}c", noError, `a{{ block body }}b{{ endblock }}c`},
+	{"nested blocks", "{{ block a }}{{ block b }}B{{ endblock b }}{{ endblock a }}", noError,
+		`{{ block a }}{{ block b }}B{{ endblock }}{{ endblock }}`},
+	{"directives", "@import(\"fmt\")\n@params(p1 string)\n{{ p1 }}", noError, "\n\n{{ p1 }}"},
+	{"include", `a@include("b.html")c`, noError, `a@include("b.html")c`},
+	{"include with args", `@include("b.html", p11=p1, p12=author.Name, p13="x", p14=true)`, noError,
+		`@include("b.html", p11=p1, p12=author.Name, p13="x", p14=true)`},
+	{"include in block", `{{ block a }}@include("b.html"){{ endblock }}`, noError, `{{ block a }}@include("b.html"){{ endblock }}`},
+	{"cache", "@cache(a)\n@cache(b, ttl=\"90s\")\n{{ block a }}A{{ endblock }}", noError, "\n\n{{ block a }}A{{ endblock }}"},
+	{"extends", "@extends(\"base.html\")\n{{ block a }}A{{ endblock }}\n", noError, "\n{{ block a }}A{{ endblock }}\n"},
+	// Errors.
+	{"unclosed block", "{{ block a }}", hasError, ``},
+	{"unexpected endblock", "{{ endblock }}", hasError, ``},
+	{"mismatched endblock", "{{ block a }}{{ endblock b }}", hasError, ``},
+	{"redefined block", "{{ block a }}{{ endblock }}{{ block a }}{{ endblock }}", hasError, ``},
+	{"text outside of blocks", "@extends(\"base.html\")\ntext{{ block a }}{{ endblock }}", hasError, ``},
+	{"action outside of blocks", "@extends(\"base.html\"){{ p }}", hasError, ``},
+	{"extends after content", "text@extends(\"base.html\")", hasError, ``},
+	{"duplicate @extends", "@extends(\"a.html\")@extends(\"b.html\")", hasError, ``},
+	{"include outside of blocks", "@extends(\"base.html\")\n@include(\"b.html\")", hasError, ``},
+	{"include without path", "@include(p1)", hasError, ``},
+	{"include positional arg", `@include("b.html", p1)`, hasError, ``},
+	{"include duplicate arg", `@include("b.html", a=1, a=2)`, hasError, ``},
+	{"unclosed call", `{{ _("a" }}`, hasError, ``},
+	{"positional after named", `{{ f(a=1, b) }}`, hasError, ``},
+	{"duplicate named arg", `{{ f(a=1, a=2) }}`, hasError, ``},
+	{"missing comma", `{{ f(a b) }}`, hasError, ``},
+	{"@cache without block", `@cache("a")`, hasError, ``},
+	{"duplicate @cache", "@cache(a)@cache(a, ttl=\"1h\")", hasError, ``},
+	{"@cache unknown option", `@cache(a, size="1h")`, hasError, ``},
+	{"@cache bad ttl", `@cache(a, ttl="hour")`, hasError, ``},
+	{"duplicate @params", "@params(a string)@params(b string)", hasError, ``},
+	{"duplicate param", "@params(\na string\na int\n)", hasError, ``},
+	{"param without type", "@params(a)", hasError, ``},
+	{"bad map type", "@params(a map[string int)", hasError, ``},
+	{"required with default", `@params(a string required = "x")`, hasError, ``},
+	{"bad default", "@params(a string = b)", hasError, ``},
+}
+
+func TestParse(t *testing.T) {
+	textFormat = "%s"
+	for _, test := range parseTests {
+		tmpl, err := New(test.name).Parse(test.input, "", "", "", make(map[string]*Tree))
+		switch {
+		case err == nil && !test.ok:
+			t.Errorf("%q: expected error; got none", test.name)
+			continue
+		case err != nil && test.ok:
+			t.Errorf("%q: unexpected error: %v", test.name, err)
+			continue
+		case err != nil && !test.ok:
+			// expected error, got one
+			continue
+		}
+		if result := tmpl.Root.String(); result != test.result {
+			t.Errorf("%s=(%q): got\n\t%v\nexpected\n\t%v", test.name, test.input, result, test.result)
+		}
+	}
+}
+
+func TestParseDirectives(t *testing.T) {
+	input := `@extends("layout.html")
+@import(
+	"github.com/strongo/templates/prototype"
+	m "github.com/strongo/templates/prototype/models"
+)
+@params(
+	p1 string
+	p2 int
+)
+@cache(content, ttl="1h")
+{{ block content }}{{ p1 }}{{ endblock }}`
+	tree, err := New("index.html").Parse(input, "", "", "", make(map[string]*Tree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Extends == nil || tree.Extends.Path != "layout.html" {
+		t.Errorf("unexpected @extends: %v", tree.Extends)
+	}
+	if len(tree.Imports) != 2 || tree.Imports[1].String() != `m "github.com/strongo/templates/prototype/models"` {
+		t.Errorf("unexpected @import: %v", tree.Imports)
+	}
+	if s := tree.Params.String(); s != "@params(p1 string\np2 int)" {
+		t.Errorf("unexpected @params: %v", s)
+	}
+	if cache := tree.Cache("content"); cache == nil || cache.String() != `@cache(content, ttl="1h0m0s")` {
+		t.Errorf("unexpected @cache: %v", tree.Caches)
+	}
+	if len(tree.Blocks) != 1 || tree.Block("content") == nil {
+		t.Errorf("unexpected blocks: %v", tree.Blocks)
+	}
+}
+
+func TestInspect(t *testing.T) {
+	input := "{{ block title }}{{ _(\"Hi\") }}{{ endblock }}\n" +
+		"{{ block content }}\n{{ _n(\"%d item\", \"%d items\", count, name=_(\"you\")) }}{{ endblock }}"
+	tree, err := New("index.html").Parse(input, "", "", "", make(map[string]*Tree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	Inspect(tree.Root, func(node Node) bool {
+		if call, ok := node.(*CallNode); ok {
+			calls = append(calls, fmt.Sprintf("%s:%d", call.Func.Ident, tree.LineNumber(call)))
+		}
+		return true
+	})
+	if s := strings.Join(calls, " "); s != "_:1 _n:3 _:3" {
+		t.Errorf("unexpected calls: %s", s)
+	}
+	count := 0
+	Inspect(tree.Root, func(node Node) bool {
+		count++
+		_, isBlock := node.(*BlockNode)
+		return !isBlock
+	})
+	if count != 4 {
+		t.Errorf("expected the root, 2 blocks and the text between them, inspected %d nodes", count)
+	}
+}
+
+var paramsTests = []struct {
+	input  string
+	result string
+}{
+	{"@params(p1 string)", "@params(p1 string)"},
+	{"@params(block string, endblock int)", "@params(block string\nendblock int)"},
+	{"@params(p1 string required  p2 int optional)", "@params(p1 string required\np2 int)"},
+	{"@params(\n\tp1 *models.Author required\n\tp2 []int\n)", "@params(p1 *models.Author required\np2 []int)"},
+	{"@params(p1 map[string][]*m.Book, p2 map[int]bool)", "@params(p1 map[string][]*m.Book\np2 map[int]bool)"},
+	{`@params(title string = "Hi", count int = -1, rate float64 optional = 0.5, on bool = true, a *m.A = nil)`,
+		`@params(title string = "Hi"` + "\ncount int = -1\nrate float64 = 0.5\non bool = true\na *m.A = nil)"},
+}
+
+func TestParseParams(t *testing.T) {
+	for _, test := range paramsTests {
+		tree, err := New("params").Parse(test.input, "", "", "", make(map[string]*Tree))
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+			continue
+		}
+		if result := tree.Params.String(); result != test.result {
+			t.Errorf("%q: got\n\t%v\nexpected\n\t%v", test.input, result, test.result)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package templates
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MissingParamError is returned by generated payload constructors
+// when a param marked as required in @params is not set.
+type MissingParamError struct {
+	Template string // path of the template
+	Param    string // name of the param as declared in @params
+}
+
+func (e MissingParamError) Error() string {
+	return fmt.Sprintf("%s: required param %q is missing", e.Template, e.Param)
+}
+
+// IsZero reports whether v is the zero value of its type.
+// Generated code uses it to check params of types it can not compare directly.
+func IsZero(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	return reflect.ValueOf(v).IsZero()
+}
@@ -0,0 +1,93 @@
+package templates
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+)
+
+// pipe holds components deferred by out-of-order rendering of a page.
+type pipe struct {
+	mutex   sync.Mutex
+	lastID  int
+	pending []*pagelet
+}
+
+// pagelet is a deferred component with its placeholder.
+type pagelet struct {
+	id        string // id of the placeholder element
+	component *StrongoComponent
+	render    RenderFunc
+}
+
+// chunk is the output of a pagelet rendered once its data is ready.
+type chunk struct {
+	id     string
+	output *bytes.Buffer
+	err    error
+}
+
+// WithOutOfOrder returns a copy of the render context rendering slow components out of order, BigPipe style.
+// A component whose data is not ready when it is rendered writes an empty placeholder and rendering continues.
+// RenderPending then writes HTML of such components as their data gets ready, each followed by
+// a small inline script moving it into the placeholder.
+func (c RenderContext) WithOutOfOrder() RenderContext {
+	c.pipe = new(pipe)
+	return c
+}
+
+// postpone writes a placeholder for the component and defers its rendering to RenderPending.
+func (p *pipe) postpone(rc RenderContext, component *StrongoComponent, render RenderFunc) error {
+	p.mutex.Lock()
+	p.lastID++
+	id := "strongo-" + strconv.Itoa(p.lastID)
+	p.pending = append(p.pending, &pagelet{id: id, component: component, render: render})
+	p.mutex.Unlock()
+	_, err := rc.WriteString(`<template id="` + id + `"></template>`)
+	return err
+}
+
+// take returns the pending pagelets and forgets them.
+func (p *pipe) take() []*pagelet {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pending := p.pending
+	p.pending = nil
+	return pending
+}
+
+// RenderPending writes components deferred by out-of-order rendering in the order their data gets ready.
+// Generated templates call it at the end of Render, so chunks follow the page and browsers append them to the body.
+// It does nothing if the render context renders in order.
+func (c RenderContext) RenderPending() error {
+	if c.pipe == nil {
+		return nil
+	}
+	pending := c.pipe.take()
+	if len(pending) == 0 {
+		return nil
+	}
+	ctx := c.Ctx()
+	chunks := make(chan chunk, len(pending)) // buffered so that renders do not leak when we return early
+	for _, p := range pending {
+		go func(p *pagelet) {
+			output := new(bytes.Buffer)
+			rc := RenderContext{Writer: output, Context: ctx} // nested components are rendered in order
+			err := p.component.render(rc, p.component.waitData(ctx), p.render)
+			chunks <- chunk{id: p.id, output: output, err: err}
+		}(p)
+	}
+	for range pending {
+		c.flushAtComponent()
+		chunk := <-chunks
+		if chunk.err != nil {
+			return chunk.err
+		}
+		if _, err := c.WriteString(`<template id="` + chunk.id + `-content">` + chunk.output.String() + "</template>" +
+			`<script>(function(){var t=document.getElementById("` + chunk.id + `-content");` +
+			`document.getElementById("` + chunk.id + `").replaceWith(t.content);t.remove()})()</script>`); err != nil {
+			return err
+		}
+	}
+	return nil
+}
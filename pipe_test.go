@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOutOfOrder(t *testing.T) {
+	newComponent := func(name string, delay time.Duration) (*StrongoComponent, RenderFunc) {
+		component := NewStrongoComponent(nil)
+		if delay == 0 {
+			component.OnDataReady(nil)
+		} else {
+			time.AfterFunc(delay, func() {
+				component.OnDataReady(nil)
+			})
+		}
+		return component, func(c RenderContext) error {
+			_, err := c.WriteString(name)
+			return err
+		}
+	}
+	slow, renderSlow := newComponent("slow", 20*time.Millisecond)
+	slower, renderSlower := newComponent("slower", 40*time.Millisecond)
+	ready, renderReady := newComponent("ready", 0)
+
+	writer := new(bytes.Buffer)
+	c := RenderContext{Writer: writer}.WithOutOfOrder()
+	started := time.Now()
+	for _, err := range []error{
+		slower.RenderWhenReady(c, renderSlower),
+		slow.RenderWhenReady(c, renderSlow),
+		ready.RenderWhenReady(c, renderReady),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed > 10*time.Millisecond {
+		t.Errorf("components that are not ready should not block rendering, took %v", elapsed)
+	}
+	if s := writer.String(); s != `<template id="strongo-1"></template><template id="strongo-2"></template>ready` {
+		t.Errorf("unexpected output before RenderPending: %s", s)
+	}
+	if err := c.RenderPending(); err != nil {
+		t.Fatal(err)
+	}
+	s := writer.String()
+	slowAt, slowerAt := strings.Index(s, `<template id="strongo-2-content">slow</template>`), strings.Index(s, `<template id="strongo-1-content">slower</template>`)
+	if slowAt < 0 || slowerAt < 0 || slowAt > slowerAt {
+		t.Errorf("chunks should be written in order their data gets ready, got: %s", s)
+	}
+	if !strings.Contains(s, `document.getElementById("strongo-1").replaceWith(t.content)`) {
+		t.Errorf("chunk should be moved into the placeholder, got: %s", s)
+	}
+}
+
+func TestOutOfOrderCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := RenderContext{Writer: new(bytes.Buffer), Context: ctx}.WithOutOfOrder()
+	NewStrongoComponent(nil).RenderWhenReady(c, func(c RenderContext) error {
+		t.Error("data is never ready")
+		return nil
+	})
+	cancel()
+	if err := c.RenderPending(); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestRenderPendingInOrder(t *testing.T) {
+	if err := (RenderContext{Writer: new(bytes.Buffer)}).RenderPending(); err != nil {
+		t.Error(err)
+	}
+}
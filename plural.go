@@ -0,0 +1,187 @@
+package templates
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PluralRule returns the index of the plural form for n: 0 for the singular of most languages,
+// then forms in the order of CLDR categories used by gettext catalogs, e.g. one, few, many for Russian.
+type PluralRule func(n int) int
+
+// pluralRule holds the rule of a language, the number of its plural forms
+// and the rule as a C expression of gettext Plural-Forms headers.
+type pluralRule struct {
+	forms int
+	rule  PluralRule
+	expr  string
+}
+
+func oneOther(n int) int {
+	if n == 1 {
+		return 0
+	}
+	return 1
+}
+
+var pluralRules = map[string]pluralRule{}
+
+func init() {
+	for _, language := range []string{"ja", "ko", "zh", "vi", "th", "id", "ms", "lo", "km", "my"} {
+		pluralRules[language] = pluralRule{1, func(n int) int { return 0 }, "0"}
+	}
+	for _, language := range []string{"fr", "pt"} {
+		pluralRules[language] = pluralRule{2, func(n int) int {
+			if n <= 1 {
+				return 0
+			}
+			return 1
+		}, "(n > 1)"}
+	}
+	pluralRules["pt_pt"] = pluralRule{2, oneOther, "(n != 1)"}
+	for _, language := range []string{"ru", "uk", "be"} {
+		pluralRules[language] = pluralRule{3, func(n int) int {
+			switch {
+			case n%10 == 1 && n%100 != 11:
+				return 0
+			case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+				return 1
+			default:
+				return 2
+			}
+		}, "(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<12 || n%100>14) ? 1 : 2)"}
+	}
+	pluralRules["pl"] = pluralRule{3, func(n int) int {
+		switch {
+		case n == 1:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		default:
+			return 2
+		}
+	}, "(n==1 ? 0 : n%10>=2 && n%10<=4 && (n%100<12 || n%100>14) ? 1 : 2)"}
+	for _, language := range []string{"cs", "sk"} {
+		pluralRules[language] = pluralRule{3, func(n int) int {
+			switch {
+			case n == 1:
+				return 0
+			case n >= 2 && n <= 4:
+				return 1
+			default:
+				return 2
+			}
+		}, "(n==1 ? 0 : n>=2 && n<=4 ? 1 : 2)"}
+	}
+	pluralRules["lt"] = pluralRule{3, func(n int) int {
+		switch {
+		case n%100 >= 11 && n%100 <= 19:
+			return 2
+		case n%10 == 1:
+			return 0
+		case n%10 >= 2:
+			return 1
+		default:
+			return 2
+		}
+	}, "(n%100>=11 && n%100<=19 ? 2 : n%10==1 ? 0 : n%10>=2 ? 1 : 2)"}
+	pluralRules["ar"] = pluralRule{6, func(n int) int {
+		switch {
+		case n == 0:
+			return 0
+		case n == 1:
+			return 1
+		case n == 2:
+			return 2
+		case n%100 >= 3 && n%100 <= 10:
+			return 3
+		case n%100 >= 11:
+			return 4
+		default:
+			return 5
+		}
+	}, "(n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5)"}
+}
+
+// rule returns the plural rule of the locale, or of its base language; "one" and "other" by default.
+func rule(locale string) pluralRule {
+	locale = normalizeLocale(locale)
+	if r, ok := pluralRules[locale]; ok {
+		return r
+	}
+	if r, ok := pluralRules[baseLanguage(locale)]; ok {
+		return r
+	}
+	return pluralRule{2, oneOther, "(n != 1)"}
+}
+
+// PluralForm returns the index of the plural form for n in the locale.
+func PluralForm(locale string, n int) int {
+	if n < 0 {
+		n = -n
+	}
+	return rule(locale).rule(n)
+}
+
+// PluralForms returns the number of plural forms of the locale.
+func PluralForms(locale string) int {
+	return rule(locale).forms
+}
+
+// PluralFormsHeader returns the Plural-Forms header of gettext catalogs of the locale,
+// e.g. "nplurals=2; plural=(n != 1);".
+func PluralFormsHeader(locale string) string {
+	r := rule(locale)
+	return fmt.Sprintf("nplurals=%d; plural=%s;", r.forms, r.expr)
+}
+
+// FormatPlural returns the message with %d replaced by n.
+func FormatPlural(message string, n int) string {
+	return strings.Replace(message, "%d", strconv.Itoa(n), -1)
+}
+
+// FormatMessage fills named placeholders of the message, e.g. {name}, with the args.
+// Placeholders without an arg are left as they are.
+func FormatMessage(message string, args map[string]interface{}) string {
+	if len(args) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(message, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(message[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		name := message[start+1 : end]
+		value, ok := args[name]
+		if !ok || !isPlaceholderName(name) {
+			b.WriteString(message[:start+1])
+			message = message[start+1:]
+			continue
+		}
+		b.WriteString(message[:start])
+		fmt.Fprint(&b, value)
+		message = message[end+1:]
+	}
+	b.WriteString(message)
+	return b.String()
+}
+
+func isPlaceholderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package templates
+
+import (
+	"testing"
+)
+
+func TestPluralForm(t *testing.T) {
+	for _, test := range []struct {
+		locale string
+		forms  int
+		n      []int
+		form   []int
+	}{
+		{"en_US", 2, []int{0, 1, 2, 11, 21}, []int{1, 0, 1, 1, 1}},
+		{"ru_RU", 3, []int{0, 1, 2, 5, 11, 12, 21, 22, 25, 101, 111, 112, -1}, []int{2, 0, 1, 2, 2, 2, 0, 1, 2, 0, 2, 2, 0}},
+		{"pl", 3, []int{1, 2, 5, 21, 22}, []int{0, 1, 2, 2, 1}},
+		{"cs_CZ", 3, []int{1, 2, 4, 5}, []int{0, 1, 1, 2}},
+		{"fr", 2, []int{0, 1, 2}, []int{0, 0, 1}},
+		{"pt_PT", 2, []int{0, 1, 2}, []int{1, 0, 1}},
+		{"lt", 3, []int{1, 2, 10, 11, 21}, []int{0, 1, 2, 2, 0}},
+		{"ar", 6, []int{0, 1, 2, 3, 11, 100}, []int{0, 1, 2, 3, 4, 5}},
+		{"ja", 1, []int{0, 1, 2}, []int{0, 0, 0}},
+		{"xx", 2, []int{1, 2}, []int{0, 1}},
+	} {
+		if forms := PluralForms(test.locale); forms != test.forms {
+			t.Errorf("%s: expected %d forms, got %d", test.locale, test.forms, forms)
+		}
+		for i, n := range test.n {
+			if form := PluralForm(test.locale, n); form != test.form[i] {
+				t.Errorf("%s: expected form %d for %d, got %d", test.locale, test.form[i], n, form)
+			}
+		}
+	}
+}
+
+func TestFormatMessage(t *testing.T) {
+	args := map[string]interface{}{"name": "Ann", "count": 3}
+	for message, formatted := range map[string]string{
+		"Hi, {name}!":            "Hi, Ann!",
+		"{name} has {count} {x}": "Ann has 3 {x}",
+		"{ name } {name":         "{ name } {name",
+		"{{name}}":               "{Ann}",
+		"no placeholders":        "no placeholders",
+	} {
+		if s := FormatMessage(message, args); s != formatted {
+			t.Errorf("%q: expected %q, got %q", message, formatted, s)
+		}
+	}
+}
+
+func TestCatalogsNGetText(t *testing.T) {
+	catalogs := NewCatalogs("en_US")
+	catalogs.Add("ru", Messages{"%d message": "%d сообщение\x00%d сообщения\x00%d сообщений"})
+	catalogs.Add("ru_RU", Messages{"%d file": "%d файл\x00%d файла"}) // the last form is missing
+	ru, en := catalogs.I18n("ru_RU"), catalogs.I18n("en_US")
+	for _, test := range []struct {
+		i18n     I18n
+		singular string
+		n        int
+		text     string
+	}{
+		{ru, "%d message", 1, "1 сообщение"},
+		{ru, "%d message", 3, "3 сообщения"},
+		{ru, "%d message", 11, "11 сообщений"},
+		{ru, "%d file", 2, "2 файла"},
+		{ru, "%d file", 5, "5 files"},
+		{en, "%d message", 1, "1 message"},
+		{en, "%d message", 0, "0 messages"},
+	} {
+		plural := test.singular + "s"
+		if text := test.i18n.NGetText(test.singular, plural, test.n); text != test.text {
+			t.Errorf("%s %d: expected %q, got %q", LocaleOf(test.i18n), test.n, test.text, text)
+		}
+	}
+	if text := ru.GetText("%d message"); text != "%d сообщение" {
+		t.Errorf("GetText should return the first plural form, got %q", text)
+	}
+	if text := ru.Format(ru.NGetText("%d message", "%d messages", 2), nil); text != "2 сообщения" {
+		t.Errorf("unexpected text %q", text)
+	}
+}
+
+func TestPluralFormsHeader(t *testing.T) {
+	for locale := range pluralRules {
+		nplurals, rule, err := ParsePluralForms(PluralFormsHeader(locale))
+		if err != nil {
+			t.Errorf("%s: %v", locale, err)
+			continue
+		}
+		if nplurals != PluralForms(locale) {
+			t.Errorf("%s: expected %d forms, got %d", locale, PluralForms(locale), nplurals)
+		}
+		for n := 0; n < 300; n++ {
+			if form := rule(n); form != PluralForm(locale, n) {
+				t.Errorf("%s: the header gives form %d for %d, the rule %d", locale, form, n, PluralForm(locale, n))
+				break
+			}
+		}
+	}
+}
@@ -1,30 +1,36 @@
 package code
 
 import (
+	"path/filepath"
+	"runtime"
+
 	"github.com/strongo/templates"
 )
 
-type I18nInCode struct {
-	messages map[string]string
+// DefaultLocale is the locale of source strings of the templates.
+const DefaultLocale = "en_US"
+
+// LocalesDir is the directory of gettext catalogs of the prototype, e.g. ru_RU.po.
+var LocalesDir = localesDir()
+
+func localesDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "locales")
 }
 
-func CreateMessages() map[string]I18nInCode {
-	return map[string]I18nInCode{
-		"ru_RU": I18nInCode{
-			messages: map[string]string{
-				"Welcome to page": "Добро пожаловать на страницу",
-				"Put your content here.": "Разместите ваш контент здесь",
-			},
-		},
+// CreateCatalogs loads the gettext catalogs of LocalesDir, reloading changed files if reload is set.
+func CreateCatalogs(reload bool) *templates.Catalogs {
+	catalogs, err := templates.LoadCatalogs(LocalesDir, DefaultLocale, reload)
+	if err != nil {
+		panic(err)
 	}
+	return catalogs
 }
 
-var I18Storage map[string]I18nInCode = CreateMessages()
+var Catalogs = CreateCatalogs(false)
 
+// GetI18N returns translations to the locale falling back to its base language,
+// the default locale and source strings.
 func GetI18N(locale string) templates.I18n {
-	return templates.I18n(I18Storage[locale])
-}
-
-func (i18n I18nInCode) GetText(key string) string {
-	return i18n.messages[key]
+	return Catalogs.I18n(locale)
 }
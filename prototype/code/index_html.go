@@ -1,6 +1,9 @@
 package code
 
 import (
+	"context"
+	"time"
+
 	"github.com/strongo/templates"
 	"github.com/strongo/templates/prototype"
 )
@@ -15,26 +18,35 @@ type Index_html struct {
 	extends layout_html
 	payload Payload_Index_html
 	component *templates.StrongoComponent
-	authorCards []*prototype.AuthorCard
+	authorCards []templates.Component
 }
 
+// DataProvider is where author cards of the index page load authors from.
+var DataProvider = prototype.NewDataProvider(time.Millisecond*10)
+
 func NewIndex_html(locale string, payload Payload_Index_html) templates.Template {
 
-	authorCards := make([]*prototype.AuthorCard, len(payload.AuthorIds))
+	i18n := GetI18N(locale)
+	authorCards := make([]templates.Component, len(payload.AuthorIds))
 	components := make([]templates.IStrongoComponent, len(authorCards))
+	authors := prototype.NewAuthorsLoader(DataProvider, prototype.AuthorsCache)
 	for i, authorId := range payload.AuthorIds {
 		authorCardPayload := prototype.AuthorCard_Payload{AuthorId: authorId}
-		authorCard := prototype.NewAuthorCard(authorCardPayload)
+		authorCard := prototype.AuthorCardCache.Component(templates.FragmentKey{
+			Template: "AuthorCard",
+			Payload: templates.PayloadHash(authorCardPayload),
+			Locale: templates.LocaleOf(i18n),
+		}, 0, prototype.NewAuthorCard(authorCardPayload, authors))
 		authorCards[i] = authorCard
 		components[i] = authorCard
 	}
-	template := Index_html{
-		i18n: I18Storage[locale],
+	template := &Index_html{
+		i18n: i18n,
 		payload: payload,
 		authorCards: authorCards,
 	}
 
-	template.component = templates.NewStrongoComponent(components)
+	template.component = templates.NewStrongoComponent(components, authors)
 
 	template.extends = New_layout_html(
 		template.i18n,
@@ -44,46 +56,78 @@ func NewIndex_html(locale string, payload Payload_Index_html) templates.Template
 	return template
 }
 
-func (t Index_html) GetData() {
-	t.component.GetData()
+func (t *Index_html) Name() string {
+	return "index.html"
 }
 
+func (t *Index_html) Path() string {
+	return "index.html"
+}
 
+func (t *Index_html) Source() string {
+	return ""
+}
 
-func (t Index_html) Render(c templates.RenderContext) error {
-	layoutPayload := Payload_Layout_html{BgColor: t.payload.BgColor}
-	return t.extends.Render(c, layoutPayload)
+func (t *Index_html) GetData(ctx context.Context) error {
+	return t.component.GetData(ctx)
 }
 
-func (t Index_html) RenderBlock_head_title(c templates.RenderContext) error {
+func (t *Index_html) Render(c templates.RenderContext) error {
+	if err := t.extends.Render(c); err != nil {
+		return err
+	}
+	return c.RenderPending()
+}
+
+func (t *Index_html) RenderBlock_head_title(c templates.RenderContext) error {
 	_, err := c.WriteString("Index.html")
 	return err
 }
 
-func (t Index_html) RenderBlock_page_title(c templates.RenderContext) error {
+func (t *Index_html) RenderBlock_page_title(c templates.RenderContext) error {
 	_, err := c.WriteString("Index.html!")
 	return err
 }
 
-/*
-func (t Index_html) RenderBlock_menu(c templates.RenderContext) error {
+func (t *Index_html) RenderBlock_menu(c templates.RenderContext) error {
+	key := templates.FragmentKey{
+		Template: "index.html",
+		Block: "menu",
+		Payload: templates.PayloadHash(t.payload),
+		Locale: templates.LocaleOf(t.i18n),
+	}
+	return templates.Fragments.Render(c, key, time.Hour, t.renderBlock_menu)
+}
+
+func (t *Index_html) renderBlock_menu(c templates.RenderContext) error {
 	return t.extends.RenderBlock_menu(c)
 }
-*/
 
-func (t Index_html) RenderBlock_content(c templates.RenderContext) error {
+func (t *Index_html) RenderBlock_content(c templates.RenderContext) error {
 	if _, err := c.WriteString("<p>"); err != nil {
 		return err
 	}
 
-	if _, err := c.WriteString(t.i18n.GetText("Put your content here.")); err != nil {
+	if _, err := c.WriteString(templates.EscapeHTML(t.i18n.GetText("Put your content here."))); err != nil {
 		return err
 	}
 
-	for _, authorCard := range t.authorCards{
-		c.WriteString("\n<li>")
-		authorCard.Render(c)
-		c.WriteString("</li>")
+	authorCards := make([]templates.RenderFunc, len(t.authorCards))
+	for i, authorCard := range t.authorCards{
+		authorCard := authorCard
+		authorCards[i] = func(c templates.RenderContext) error {
+			if _, err := c.WriteString("\n<li>"); err != nil {
+				return err
+			}
+			if err := authorCard.Render(c); err != nil {
+				return err
+			}
+			_, err := c.WriteString("</li>")
+			return err
+		}
+	}
+	if err := templates.RenderParallel(c, authorCards...); err != nil {
+		return err
 	}
 
 	if _, err := c.WriteString("</p>"); err != nil {
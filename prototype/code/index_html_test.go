@@ -1,9 +1,18 @@
 package code
 
 import (
+	"context"
+	"html"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 	"bytes"
 	"github.com/strongo/templates"
+	"github.com/strongo/templates/prototype"
 )
 
 
@@ -18,7 +27,7 @@ func Test_Index_html(t *testing.T) {
 	writer := new(bytes.Buffer)
 	payload := GetIndexHtmlPayload()
 	indexHtml := NewIndex_html("ru_RU", payload)
-	indexHtml.GetData()
+	indexHtml.GetData(context.Background())
 	indexHtml.Render(templates.RenderContext{Writer: writer})
 	s := writer.String()
 	t.Log(s)
@@ -27,12 +36,210 @@ func Test_Index_html(t *testing.T) {
 //	}
 }
 
-func Benchmark_Index_html(b *testing.B) {
+func Test_Index_html_Cancel(t *testing.T) {
+	defer withCaches(nil, nil)()
+	defer func(dataProvider prototype.DataProvider) {
+		DataProvider = dataProvider
+	}(DataProvider)
+	// authors are never loaded as the clock does not move: only the cancellation ends the render
+	DataProvider = prototype.DataProvider{Latency: time.Millisecond, Clock: templates.NewFakeClock(time.Now())}
+
+	goroutines := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	indexHtml := NewIndex_html("ru_RU", GetIndexHtmlPayload())
+	indexHtml.GetData(ctx)
+	cancel()
+	if err := indexHtml.Render(templates.NewRenderContext(ctx, new(bytes.Buffer))); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+	for i := 0; runtime.NumGoroutine() > goroutines && i < 1000; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > goroutines {
+		t.Errorf("%d goroutines leaked", n-goroutines)
+	}
+}
+
+// withCaches replaces the caches of author cards and authors until the returned func restores them.
+func withCaches(authorCards *templates.FragmentCache, authors *templates.DataCache) (restore func()) {
+	previousAuthorCards, previousAuthors := prototype.AuthorCardCache, prototype.AuthorsCache
+	prototype.AuthorCardCache, prototype.AuthorsCache = authorCards, authors
+	return func() {
+		prototype.AuthorCardCache, prototype.AuthorsCache = previousAuthorCards, previousAuthors
+	}
+}
+
+func Test_Index_html_TimeBudget(t *testing.T) {
+	defer withCaches(nil, nil)()
+	defer func(budget time.Duration) {
+		prototype.AuthorCardTimeBudget = budget
+	}(prototype.AuthorCardTimeBudget)
+	prototype.AuthorCardTimeBudget = time.Millisecond
+
+	writer := new(bytes.Buffer)
+	indexHtml := NewIndex_html("ru_RU", GetIndexHtmlPayload())
+	indexHtml.GetData(context.Background())
+	if err := indexHtml.Render(templates.RenderContext{Writer: writer}); err != nil {
+		t.Fatal(err)
+	}
+	if s := writer.String(); !strings.Contains(s, "Author #101 is not available") {
+		t.Errorf("late author cards should render placeholders, got:\n%s", s)
+	}
+}
+
+func Test_Index_html_OutOfOrder(t *testing.T) {
+	defer withCaches(nil, nil)()
+	writer := new(bytes.Buffer)
+	indexHtml := NewIndex_html("ru_RU", GetIndexHtmlPayload())
+	indexHtml.GetData(context.Background())
+	if err := indexHtml.Render(templates.RenderContext{Writer: writer}.WithOutOfOrder()); err != nil {
+		t.Fatal(err)
+	}
+	s := writer.String()
+	if !strings.Contains(s, `<li><template id="strongo-1"></template></li>`) || !strings.Contains(s, `<template id="strongo-1-content"><div>`) {
+		t.Errorf("author cards should be rendered out of order, got:\n%s", s)
+	}
+}
+
+func Test_Index_html_FragmentCache(t *testing.T) {
+	defer withCaches(templates.NewFragmentCache(templates.NewLRUStorage(1<<20), time.Minute), prototype.AuthorsCache)()
+	render := func(c templates.RenderContext) string {
+		writer := new(bytes.Buffer)
+		c.Writer = writer
+		indexHtml := NewIndex_html("ru_RU", GetIndexHtmlPayload())
+		indexHtml.GetData(context.Background())
+		if err := indexHtml.Render(c); err != nil {
+			t.Fatal(err)
+		}
+		return writer.String()
+	}
+	s := render(templates.RenderContext{})
+	if cached := render(templates.RenderContext{}.WithOutOfOrder()); cached != s {
+		t.Errorf("cached author cards should be rendered in place, got:\n%s\nexpected:\n%s", cached, s)
+	}
+}
+
+func Test_Index_html_Handler(t *testing.T) {
+	handler := templates.NewHandler(func(r *http.Request, locale string) (templates.Template, error) {
+		return NewIndex_html(locale, GetIndexHtmlPayload()), nil
+	}, Catalogs.Locales()...)
+	for acceptLanguage, welcome := range map[string]string{
+		"ru-RU":                  "Добро пожаловать на страницу",
+		"de-DE, ru;q=0.8":        "Добро пожаловать на страницу",
+		"de-DE, en-GB;q=0.8, ru;q=0.5": "Welcome to page",
+	} {
+		request := httptest.NewRequest("GET", "/", nil)
+		request.Header.Set("Accept-Language", acceptLanguage)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK || !strings.Contains(recorder.Body.String(), welcome) {
+			t.Errorf("%q: unexpected response %d:\n%s", acceptLanguage, recorder.Code, recorder.Body.String())
+		}
+	}
+}
+
+func Test_AuthorsLoader_Cache(t *testing.T) {
+	clock := templates.NewFakeClock(time.Now())
+	dataProvider := prototype.DataProvider{Latency: 10 * time.Millisecond, Clock: clock}
+	cache := templates.NewDataCache(time.Minute, time.Hour)
+	cache.Clock = clock
+	load := func() *prototype.Author {
+		authors := prototype.NewAuthorsLoader(dataProvider, cache)
+		loaded := make(chan *prototype.Author, 1)
+		authors.Load(101, func(value interface{}, err error) {
+			author, _ := value.(*prototype.Author)
+			loaded <- author
+		})
+		go authors.Dispatch(context.Background())
+		return <-loaded
+	}
+	waitForRequest := func() {
+		for clock.Timers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		clock.Advance(dataProvider.Latency)
+	}
+
+	go waitForRequest()
+	if author := load(); author == nil || author.Id != 101 {
+		t.Fatalf("unexpected author: %v", author)
+	}
+	if author := load(); author == nil || clock.Timers() != 0 {
+		t.Errorf("cached author should be loaded without a request, got %v", author)
+	}
+	clock.Advance(2 * time.Minute)
+	if author := load(); author == nil {
+		t.Error("stale author should be loaded without waiting for the request")
+	}
+	waitForRequest() // the background refresh
+}
+
+// benchmarkIndex renders the index page with authors loaded without latency.
+func benchmarkIndex(b *testing.B) {
+	defer func(dataProvider prototype.DataProvider) {
+		DataProvider = dataProvider
+	}(DataProvider)
+	DataProvider = prototype.DataProvider{}
+
 	writer := new(bytes.Buffer)
 	payload := GetIndexHtmlPayload()
 	for i := 0; i < b.N; i++ {
+		writer.Reset()
 		indexHtml := NewIndex_html("ru_RU", payload)
-		indexHtml.GetData()
+		indexHtml.GetData(context.Background())
 		indexHtml.Render(templates.RenderContext{Writer: writer})
 	}
 }
+
+// Benchmark_Index_html renders author cards and loads authors on every iteration.
+func Benchmark_Index_html(b *testing.B) {
+	defer withCaches(nil, nil)()
+	benchmarkIndex(b)
+}
+
+// Benchmark_Index_html_Cached takes author cards from the cache after the first iteration.
+func Benchmark_Index_html_Cached(b *testing.B) {
+	defer withCaches(templates.NewFragmentCache(templates.NewLRUStorage(1<<20), time.Hour), templates.NewDataCache(time.Hour, 0))()
+	benchmarkIndex(b)
+}
+
+// tables are CPU heavy sibling components rendering rows of escaped cells.
+func tables(count, rows int) []templates.RenderFunc {
+	renders := make([]templates.RenderFunc, count)
+	for i := range renders {
+		renders[i] = func(c templates.RenderContext) error {
+			c.WriteString("<table>")
+			for row := 0; row < rows; row++ {
+				c.WriteString("<tr><td>")
+				c.WriteString(html.EscapeString("Author #" + strconv.Itoa(row) + " <author@example.com>"))
+				c.WriteString("</td><td>")
+				c.WriteString(strconv.FormatFloat(float64(row)/3, 'f', 2, 64))
+				c.WriteString("</td></tr>")
+			}
+			_, err := c.WriteString("</table>")
+			return err
+		}
+	}
+	return renders
+}
+
+func Benchmark_Tables_Sequential(b *testing.B) {
+	writer := new(bytes.Buffer)
+	renders := tables(8, 1000)
+	for i := 0; i < b.N; i++ {
+		writer.Reset()
+		c := templates.RenderContext{Writer: writer}
+		for _, render := range renders {
+			render(c)
+		}
+	}
+}
+
+func Benchmark_Tables_Parallel(b *testing.B) {
+	writer := new(bytes.Buffer)
+	renders := tables(8, 1000)
+	for i := 0; i < b.N; i++ {
+		writer.Reset()
+		templates.RenderParallel(templates.RenderContext{Writer: writer}, renders...)
+	}
+}
@@ -17,6 +17,7 @@ type Payload_Layout_html struct {
 
 type layout_html struct {
 	i18n templates.I18n
+	locale int // 1 + index of the locale in inlined ones, 0 if its translations are not inlined.
 	template Layout_html
 	payload Payload_Layout_html
 }
@@ -24,13 +25,14 @@ type layout_html struct {
 func New_layout_html(i18n templates.I18n, template Layout_html, payload Payload_Layout_html) layout_html {
 	return layout_html{
 		i18n: i18n,
+		locale: templates.LocaleIndex(i18n, "en_US", "ru_RU"),
 		template: template,
 		payload: payload,
 	}
 }
 
 
-func (layout layout_html) Render(c templates.RenderContext, payload Payload_Layout_html) error {
+func (layout layout_html) Render(c templates.RenderContext) error {
 	if _, err := c.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<title>"); err != nil {
 		return err
 	}
@@ -38,12 +40,19 @@ func (layout layout_html) Render(c templates.RenderContext, payload Payload_Layo
 		return err
 	}
 	c.WriteString("</title>\n</head>\n<body style=\"background-color: ")
-	c.WriteString(payload.BgColor)
-	c.WriteString(";\">\n<h1>")
-	if _, err := c.WriteString(layout.i18n.GetText("Welcome to page")); err != nil {
-		return err
+	c.WriteString(templates.EscapeAttr(templates.FilterCSSValue(layout.payload.BgColor))) // CSS value in a quoted attribute
+	switch layout.locale {
+	case 1: // en_US
+		c.WriteString(";\">\n<h1>Welcome to page ")
+	case 2: // ru_RU
+		c.WriteString(";\">\n<h1>Добро пожаловать на страницу ")
+	default:
+		c.WriteString(";\">\n<h1>")
+		if _, err := c.WriteString(templates.EscapeHTML(layout.i18n.GetText("Welcome to page"))); err != nil {
+			return err
+		}
+		c.WriteString(" ")
 	}
-	c.WriteString(" ")
 	if err := layout.template.RenderBlock_page_title(c); err != nil {
 		return err
 	}
@@ -1,7 +1,10 @@
 package prototype
 
 import (
+	"context"
+	"strconv"
 	"time"
+
 	"github.com/strongo/templates"
 )
 
@@ -17,33 +20,102 @@ type AuthorCard_Data struct {
 type AuthorCard struct {
 	component *templates.StrongoComponent
 	payload AuthorCard_Payload
-	dataProvider DataProvider
+	authors *templates.Loader
 
 	data AuthorCard_Data
 }
 
-func NewAuthorCard(payload AuthorCard_Payload) *AuthorCard {
-	return &AuthorCard{
+// AuthorsCache keeps authors between renders; nil turns the cache off.
+var AuthorsCache = newAuthorsCache()
+
+func newAuthorsCache() *templates.DataCache {
+	cache := templates.NewDataCache(time.Minute, time.Hour)
+	cache.NegativeTTL = 10 * time.Second
+	cache.Jitter = 0.1
+	return cache
+}
+
+// NewAuthorsLoader creates a loader getting authors requested by sibling cards with one GetAuthors call.
+// Authors found in the cache are not requested; stale ones are refreshed in the background.
+func NewAuthorsLoader(dataProvider DataProvider, cache *templates.DataCache) *templates.Loader {
+	return templates.NewLoader(func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		values := make(map[interface{}]interface{}, len(keys))
+		authorIds := make([]int, 0, len(keys))
+		for _, key := range keys {
+			authorId := key.(int)
+			author, cached, err := cache.Lookup(authorId, func(ctx context.Context) (interface{}, error) {
+				return dataProvider.GetAuthor(ctx, authorId)
+			})
+			switch {
+			case !cached:
+				authorIds = append(authorIds, authorId)
+			case err == nil:
+				values[authorId] = author
+			}
+		}
+		if len(authorIds) == 0 {
+			return values, nil
+		}
+		authors, err := dataProvider.GetAuthors(ctx, authorIds)
+		if err != nil {
+			return nil, err
+		}
+		for _, authorId := range authorIds {
+			if author, ok := authors[authorId]; ok {
+				values[authorId] = author
+				cache.Set(authorId, author, nil)
+			} else {
+				cache.Set(authorId, nil, templates.ErrNotLoaded)
+			}
+		}
+		return values, nil
+	})
+}
+
+// AuthorCardTimeBudget is how long a page waits for the author of a card before rendering a placeholder.
+var AuthorCardTimeBudget = 100 * time.Millisecond
+
+// AuthorCardCache caches HTML of author cards; nil turns the cache off.
+var AuthorCardCache = templates.NewFragmentCache(templates.NewLRUStorage(1<<20), time.Minute)
+
+// NewAuthorCard creates a card getting its author through the loader shared with sibling cards.
+// If the author fails to load or is late the card renders a placeholder.
+func NewAuthorCard(payload AuthorCard_Payload, authors *templates.Loader) *AuthorCard {
+	card := &AuthorCard{
 		component: templates.NewStrongoComponent(nil),
 		payload: payload,
-		dataProvider: NewDataProvider(time.Millisecond*10),
+		authors: authors,
 	}
+	card.component.SetErrorPolicy(templates.FallbackOnError, card.renderFallback)
+	card.component.SetTimeBudget(AuthorCardTimeBudget)
+	return card
+}
+
+// Component returns the component of the card, e.g. to change its error policy.
+func (self *AuthorCard) Component() *templates.StrongoComponent {
+	return self.component
 }
 
-func (self *AuthorCard) GetData() {
-	go func(){
-		self.data = AuthorCard_Data{author: self.dataProvider.GetAuthor(self.payload.AuthorId)}
-		self.component.OnDataReady()
-	}()
+func (self *AuthorCard) GetData(ctx context.Context) error {
+	self.authors.Load(self.payload.AuthorId, func(value interface{}, err error) {
+		author, _ := value.(*Author)
+		self.data = AuthorCard_Data{author: author}
+		self.component.OnDataReady(err)
+	})
+	return nil
 }
 //1,000,000,000
 //0,011,382,440 - delay 10ms
 //0,001,263,847 - delay 1ms
 
-func (self *AuthorCard) Render(c templates.RenderContext) {
-	c.WriteString("<div>\nAuthor: ")
-	self.component.WhenDataReady()
-//	log.Print("Render: " + self.Author.Name)
-	c.WriteString(self.data.author.Name)
-	c.WriteString("\n</div>")
+func (self *AuthorCard) Render(c templates.RenderContext) error {
+	return self.component.RenderWhenReady(c, func(c templates.RenderContext) error {
+		_, err := c.WriteString("<div>\nAuthor: " + self.data.author.Name + "\n</div>")
+		return err
+	})
+}
+
+func (self *AuthorCard) renderFallback(c templates.RenderContext, err error) error {
+	_, err = c.WriteString("<div>\nAuthor #" + strconv.Itoa(self.payload.AuthorId) + " is not available\n</div>")
+	return err
 }
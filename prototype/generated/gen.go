@@ -0,0 +1,31 @@
+//go:build ignore
+// +build ignore
+
+// gen compiles the prototype templates of ../source_templates into this package
+// and, with translations of the prototype catalogs inlined, into the inlined package.
+package main
+
+import (
+	"log"
+
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/compiler/codegen"
+)
+
+func main() {
+	g := codegen.NewCodeGenerator(templates.StrongoEnvironment{})
+	if err := g.CompileDir("../source_templates", "."); err != nil {
+		log.Fatal(err)
+	}
+
+	catalogs, err := templates.LoadCatalogs("../locales", "en_US", false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	g = codegen.NewCodeGenerator(templates.StrongoEnvironment{})
+	g.Catalogs = catalogs
+	g.InlineLocales = []string{"en_US", "ru_RU"}
+	if err := g.CompileDir("../source_templates", "inlined"); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,14 @@
+// Package generated is the code generated from the prototype templates, see gen.go.
+package generated
+
+//go:generate go run gen.go
+
+import (
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/prototype/code"
+)
+
+// GetI18N returns translations of the prototype catalogs to the locale.
+func GetI18N(locale string) templates.I18n {
+	return code.GetI18N(locale)
+}
@@ -0,0 +1,81 @@
+// Code generated by strongo from include1.html. DO NOT EDIT.
+
+package generated
+
+import (
+	"context"
+
+	"github.com/strongo/templates"
+)
+
+const source_Include1_html = "@import(\n)\n\n@params(\n    p1 string\n)\n\nSome file without extending\n\n@include(\"include2.html\", p11=p1)"
+
+// Payload_Include1_html holds params of include1.html.
+type Payload_Include1_html struct {
+	P1 string
+}
+
+// NewPayload_Include1_html checks required params are set and applies defaults to optional params left with zero values.
+func NewPayload_Include1_html(payload Payload_Include1_html) (Payload_Include1_html, error) {
+	return payload, nil
+}
+
+// Include1_html renders include1.html.
+type Include1_html struct {
+	i18n    templates.I18n
+	payload Payload_Include1_html
+	err     error // Payload error returned by GetData and Render.
+}
+
+// NewInclude1_html creates include1.html template for the locale.
+func NewInclude1_html(locale string, payload Payload_Include1_html) templates.Template {
+	return newInclude1_html(GetI18N(locale), payload)
+}
+
+func newInclude1_html(i18n templates.I18n, payload Payload_Include1_html) *Include1_html {
+	payload, err := NewPayload_Include1_html(payload)
+	return &Include1_html{
+		i18n:    i18n,
+		payload: payload,
+		err:     err,
+	}
+}
+
+func (t *Include1_html) Name() string {
+	return "include1.html"
+}
+
+func (t *Include1_html) Path() string {
+	return "include1.html"
+}
+
+func (t *Include1_html) Source() string {
+	return source_Include1_html
+}
+
+func (t *Include1_html) GetData(ctx context.Context) error {
+	return t.err
+}
+
+func (t *Include1_html) Render(c templates.RenderContext) error {
+	if err := t.render(c); err != nil {
+		return err
+	}
+	return c.RenderPending()
+}
+
+func (t *Include1_html) render(c templates.RenderContext) error {
+	if t.err != nil {
+		return t.err
+	}
+	if _, err := c.WriteString("\n\n"); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("\n\nSome file without extending\n\n"); err != nil {
+		return err
+	}
+	if err := newInclude2_html(t.i18n, Payload_Include2_html{P11: t.payload.P1}).render(c); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package generated
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/prototype/generated/inlined"
+)
+
+// benchmarkIndex renders the index page the template function creates for the ru_RU locale.
+func benchmarkIndex(b *testing.B, template func(locale string) templates.Template) {
+	writer := new(bytes.Buffer)
+	for i := 0; i < b.N; i++ {
+		writer.Reset()
+		if err := template("ru_RU").Render(templates.RenderContext{Writer: writer}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Index_html(b *testing.B) {
+	benchmarkIndex(b, func(locale string) templates.Template {
+		return NewIndex_html(locale, Payload_Index_html{P1: "Author", P2: 5, BgColor: "white"})
+	})
+}
+
+func Benchmark_Index_html_Inlined(b *testing.B) {
+	benchmarkIndex(b, func(locale string) templates.Template {
+		return inlined.NewIndex_html(locale, inlined.Payload_Index_html{P1: "Author", P2: 5, BgColor: "white"})
+	})
+}
@@ -0,0 +1,13 @@
+// Package inlined is the code generated from the prototype templates with translations
+// to en_US and ru_RU inlined, see ../gen.go.
+package inlined
+
+import (
+	"github.com/strongo/templates"
+	"github.com/strongo/templates/prototype/code"
+)
+
+// GetI18N returns translations of the prototype catalogs to the locale.
+func GetI18N(locale string) templates.I18n {
+	return code.GetI18N(locale)
+}
@@ -0,0 +1,86 @@
+// Code generated by strongo from include2.html. DO NOT EDIT.
+
+package inlined
+
+import (
+	"context"
+
+	"github.com/strongo/templates"
+)
+
+const source_Include2_html = "@params(\n    p11 string required\n)\n\n<p>Included with {{ p11 }}</p>"
+
+// Payload_Include2_html holds params of include2.html.
+type Payload_Include2_html struct {
+	P11 string // required
+}
+
+// NewPayload_Include2_html checks required params are set and applies defaults to optional params left with zero values.
+func NewPayload_Include2_html(payload Payload_Include2_html) (Payload_Include2_html, error) {
+	if payload.P11 == "" {
+		return payload, templates.MissingParamError{Template: "include2.html", Param: "p11"}
+	}
+	return payload, nil
+}
+
+// Include2_html renders include2.html.
+type Include2_html struct {
+	i18n    templates.I18n
+	locale  int // 1 + index of the locale in inlined ones, 0 if its translations are not inlined.
+	payload Payload_Include2_html
+	err     error // Payload error returned by GetData and Render.
+}
+
+// NewInclude2_html creates include2.html template for the locale.
+func NewInclude2_html(locale string, payload Payload_Include2_html) templates.Template {
+	return newInclude2_html(GetI18N(locale), payload)
+}
+
+func newInclude2_html(i18n templates.I18n, payload Payload_Include2_html) *Include2_html {
+	payload, err := NewPayload_Include2_html(payload)
+	return &Include2_html{
+		i18n:    i18n,
+		locale:  templates.LocaleIndex(i18n, "en_US", "ru_RU"),
+		payload: payload,
+		err:     err,
+	}
+}
+
+func (t *Include2_html) Name() string {
+	return "include2.html"
+}
+
+func (t *Include2_html) Path() string {
+	return "include2.html"
+}
+
+func (t *Include2_html) Source() string {
+	return source_Include2_html
+}
+
+func (t *Include2_html) GetData(ctx context.Context) error {
+	return t.err
+}
+
+func (t *Include2_html) Render(c templates.RenderContext) error {
+	if err := t.render(c); err != nil {
+		return err
+	}
+	return c.RenderPending()
+}
+
+func (t *Include2_html) render(c templates.RenderContext) error {
+	if t.err != nil {
+		return t.err
+	}
+	if _, err := c.WriteString("\n\n<p>Included with "); err != nil {
+		return err
+	}
+	if _, err := c.WriteString(templates.EscapeHTML(t.payload.P11)); err != nil {
+		return err
+	}
+	if _, err := c.WriteString("</p>"); err != nil {
+		return err
+	}
+	return nil
+}
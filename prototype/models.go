@@ -1,9 +1,11 @@
 package prototype
 
 import (
+	"context"
 	"time"
 	"strconv"
 
+	"github.com/strongo/templates"
 )
 type Author struct {
 	Id int
@@ -17,24 +19,50 @@ type Book struct {
 
 type DataProvider struct {
 	Latency time.Duration
+	Clock templates.Clock // measures the latency; tests use a templates.FakeClock
 }
 
 func NewDataProvider(latency time.Duration) DataProvider {
-	return DataProvider{Latency: latency}
+	return DataProvider{Latency: latency, Clock: templates.SystemClock}
 }
 
-func (dp DataProvider) GetAuthors() map[int]*Author {
-	result := make(map[int]*Author)
-	for i := 0; i < 20; i++ {
-		result[i] = dp.GetAuthor(i)
+// GetAuthors loads authors by IDs in one round trip.
+func (dp DataProvider) GetAuthors(ctx context.Context, authorIds []int) (map[int]*Author, error) {
+	if err := dp.wait(ctx); err != nil {
+		return nil, err
 	}
-	return result
+	result := make(map[int]*Author, len(authorIds))
+	for _, authorId := range authorIds {
+		result[authorId] = newAuthor(authorId)
+	}
+	return result, nil
+}
+
+func (dp DataProvider) GetAuthor(ctx context.Context, authorId int) (*Author, error) {
+	if err := dp.wait(ctx); err != nil {
+		return nil, err
+	}
+	return newAuthor(authorId), nil
 }
 
-func (dp DataProvider) GetAuthor(authorId int) *Author {
-	if dp.Latency > 0 {
-		time.Sleep(dp.Latency)
+// wait simulates the latency of a round trip to the data store.
+func (dp DataProvider) wait(ctx context.Context) error {
+	if dp.Latency <= 0 {
+		return ctx.Err()
 	}
+	clock := dp.Clock
+	if clock == nil {
+		clock = templates.SystemClock
+	}
+	select {
+	case <-clock.After(dp.Latency):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func newAuthor(authorId int) *Author {
 	return &Author{
 		Id: authorId,
 		Name: "John Smith #" + strconv.Itoa(authorId),
@@ -0,0 +1,132 @@
+package templates
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+)
+
+// Priority orders data loading work of a render waiting for a free slot; higher runs first.
+type Priority int
+
+const (
+	PriorityBelowTheFold Priority = -1
+	PriorityNormal       Priority = 0
+	PriorityAboveTheFold Priority = 1
+)
+
+// Scheduler runs data loading work of components with bounded concurrency
+// instead of a goroutine per component.
+type Scheduler struct {
+	slots       chan struct{} // per-process limit; nil if unlimited
+	renderLimit int           // per-render limit; unlimited if 0
+	background  *renderQueue  // work scheduled outside of renders, e.g. refreshes of a DataCache
+}
+
+// DefaultScheduler is used for renders of render contexts not created with Scheduler.NewRender,
+// see NewRenderContext, and for work scheduled outside of renders.
+var DefaultScheduler = NewScheduler(256, 32)
+
+// NewScheduler creates a scheduler running at most processLimit works at once in total
+// and at most renderLimit works at once per render. A limit of 0 means no limit.
+func NewScheduler(processLimit, renderLimit int) *Scheduler {
+	s := &Scheduler{renderLimit: renderLimit}
+	s.background = &renderQueue{scheduler: s}
+	if processLimit > 0 {
+		s.slots = make(chan struct{}, processLimit)
+	}
+	return s
+}
+
+type renderQueueKey struct{}
+
+// NewRender returns a context for GetData and Render of one render;
+// work scheduled with it by Go counts against the per-render limit.
+func (s *Scheduler) NewRender(ctx context.Context) context.Context {
+	return context.WithValue(ctx, renderQueueKey{}, &renderQueue{scheduler: s})
+}
+
+// Go schedules the work of a component. It runs in another goroutine once there is a free slot
+// for the render of ctx and for the process; work of higher priority goes first.
+// Work scheduled with a context of no render shares the per-render limit with all such work.
+// The work is called even if ctx is done so that it can report the error of ctx.
+func Go(ctx context.Context, priority Priority, work func(ctx context.Context)) {
+	queue, ok := ctx.Value(renderQueueKey{}).(*renderQueue)
+	if !ok {
+		queue = DefaultScheduler.background
+	}
+	queue.schedule(&task{ctx: ctx, priority: priority, work: work})
+}
+
+type task struct {
+	ctx      context.Context
+	priority Priority
+	seq      int // tasks of the same priority run in order they are scheduled
+	work     func(ctx context.Context)
+}
+
+// renderQueue holds work of a render waiting for a per-render slot.
+type renderQueue struct {
+	scheduler *Scheduler
+
+	mutex   sync.Mutex
+	tasks   taskHeap
+	seq     int
+	running int
+}
+
+func (q *renderQueue) schedule(t *task) {
+	q.mutex.Lock()
+	q.seq++
+	t.seq = q.seq
+	heap.Push(&q.tasks, t)
+	q.start()
+	q.mutex.Unlock()
+}
+
+// start runs queued tasks while there are free per-render slots. The mutex is held.
+func (q *renderQueue) start() {
+	for len(q.tasks) > 0 && (q.scheduler.renderLimit == 0 || q.running < q.scheduler.renderLimit) {
+		q.running++
+		go q.run(heap.Pop(&q.tasks).(*task))
+	}
+}
+
+func (q *renderQueue) run(t *task) {
+	if slots := q.scheduler.slots; slots != nil {
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		case <-t.ctx.Done():
+		}
+	}
+	t.work(t.ctx)
+
+	q.mutex.Lock()
+	q.running--
+	q.start()
+	q.mutex.Unlock()
+}
+
+// taskHeap is a heap of tasks by priority, then by order they are scheduled.
+type taskHeap []*task
+
+func (h taskHeap) Len() int { return len(h) }
+
+func (h taskHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h taskHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(*task)) }
+
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	t := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return t
+}
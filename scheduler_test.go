@@ -0,0 +1,135 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// concurrency tracks the maximum number of works running at once.
+type concurrency struct {
+	mutex   sync.Mutex
+	running int
+	max     int
+}
+
+func (c *concurrency) work(done *sync.WaitGroup) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		c.mutex.Lock()
+		c.running++
+		if c.running > c.max {
+			c.max = c.running
+		}
+		c.mutex.Unlock()
+		time.Sleep(time.Millisecond)
+		c.mutex.Lock()
+		c.running--
+		c.mutex.Unlock()
+		done.Done()
+	}
+}
+
+func TestSchedulerLimits(t *testing.T) {
+	for _, test := range []struct {
+		processLimit, renderLimit, renders int
+		max                                int
+	}{
+		{0, 3, 1, 3},
+		{0, 3, 2, 6},
+		{4, 3, 2, 4},
+	} {
+		scheduler := NewScheduler(test.processLimit, test.renderLimit)
+		c := new(concurrency)
+		done := new(sync.WaitGroup)
+		for r := 0; r < test.renders; r++ {
+			ctx := scheduler.NewRender(context.Background())
+			for i := 0; i < 20; i++ {
+				done.Add(1)
+				Go(ctx, PriorityNormal, c.work(done))
+			}
+		}
+		done.Wait()
+		if c.max > test.max {
+			t.Errorf("%+v: expected at most %d works at once, got %d", test, test.max, c.max)
+		}
+	}
+}
+
+func TestSchedulerPriority(t *testing.T) {
+	ctx := NewScheduler(0, 1).NewRender(context.Background())
+	var mutex sync.Mutex
+	var order []string
+	done := new(sync.WaitGroup)
+	block := make(chan bool)
+	work := func(name string) func(ctx context.Context) {
+		return func(ctx context.Context) {
+			if name == "first" {
+				<-block // Keeps the only slot busy until all the work is queued.
+			}
+			mutex.Lock()
+			order = append(order, name)
+			mutex.Unlock()
+			done.Done()
+		}
+	}
+	done.Add(5)
+	Go(ctx, PriorityNormal, work("first"))
+	Go(ctx, PriorityBelowTheFold, work("footer"))
+	Go(ctx, PriorityNormal, work("sidebar"))
+	Go(ctx, PriorityAboveTheFold, work("header"))
+	Go(ctx, PriorityAboveTheFold, work("hero"))
+	close(block)
+	done.Wait()
+	if s := fmt.Sprint(order); s != "[first header hero sidebar footer]" {
+		t.Errorf("unexpected order: %s", s)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	scheduler := NewScheduler(1, 0)
+	busy := make(chan bool)
+	Go(scheduler.NewRender(context.Background()), PriorityNormal, func(ctx context.Context) {
+		<-busy
+	})
+	defer close(busy)
+	ctx, cancel := context.WithCancel(scheduler.NewRender(context.Background()))
+	cancel()
+	result := make(chan error)
+	Go(ctx, PriorityNormal, func(ctx context.Context) {
+		result <- ctx.Err()
+	})
+	if err := <-result; err != context.Canceled {
+		t.Errorf("work of a cancelled render should be called with the context error without waiting for a slot, got: %v", err)
+	}
+}
+
+func TestSchedulerDefault(t *testing.T) {
+	defaultScheduler := DefaultScheduler
+	defer func() { DefaultScheduler = defaultScheduler }()
+	DefaultScheduler = NewScheduler(0, 3)
+
+	for _, test := range []struct {
+		name string
+		ctx  func() context.Context
+		max  int
+	}{
+		{"render contexts", func() context.Context { return NewRenderContext(context.Background(), nil).Ctx() }, 6},
+		{"no render", context.Background, 3},
+	} {
+		c := new(concurrency)
+		done := new(sync.WaitGroup)
+		for r := 0; r < 2; r++ {
+			ctx := test.ctx()
+			for i := 0; i < 20; i++ {
+				done.Add(1)
+				Go(ctx, PriorityNormal, c.work(done))
+			}
+		}
+		done.Wait()
+		if c.max > test.max {
+			t.Errorf("%s: expected at most %d works at once, got %d", test.name, test.max, c.max)
+		}
+	}
+}
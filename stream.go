@@ -0,0 +1,70 @@
+package templates
+
+import (
+	"io"
+	"net/http"
+)
+
+// FlushPolicy defines when a streaming RenderContext flushes its writer.
+// The zero value never flushes.
+type FlushPolicy struct {
+	AtComponents bool // flush before rendering blocks waiting for data of a component
+	Threshold    int  // flush once that many bytes are written since the last flush; no threshold if 0
+}
+
+var (
+	FlushNever        = FlushPolicy{}
+	FlushAtComponents = FlushPolicy{AtComponents: true}
+)
+
+// flushWriter counts bytes written to the underlying writer and flushes it according to the policy.
+type flushWriter struct {
+	io.Writer
+	flusher   http.Flusher
+	policy    FlushPolicy
+	unflushed int // bytes written since the last flush
+}
+
+func (w *flushWriter) Write(p []byte) (n int, err error) {
+	n, err = w.Writer.Write(p)
+	w.unflushed += n
+	if err == nil && w.policy.Threshold > 0 && w.unflushed >= w.policy.Threshold {
+		w.Flush()
+	}
+	return
+}
+
+func (w *flushWriter) Flush() {
+	if w.unflushed > 0 {
+		w.flusher.Flush()
+		w.unflushed = 0
+	}
+}
+
+// WithFlushPolicy returns a copy of the render context flushing its writer according to the policy,
+// e.g. to send the <head> of a page while data of its components is still loading.
+// The render context is returned as is if the writer is not an http.Flusher.
+func (c RenderContext) WithFlushPolicy(policy FlushPolicy) RenderContext {
+	if w, ok := c.Writer.(*flushWriter); ok {
+		c.Writer = &flushWriter{Writer: w.Writer, flusher: w.flusher, policy: policy}
+		return c
+	}
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		c.Writer = &flushWriter{Writer: c.Writer, flusher: flusher, policy: policy}
+	}
+	return c
+}
+
+// Flush sends buffered output to the client if the writer is an http.Flusher.
+func (c RenderContext) Flush() {
+	if flusher, ok := c.Writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// flushAtComponent flushes the writer before waiting for component data if the flush policy asks for it.
+func (c RenderContext) flushAtComponent() {
+	if w, ok := c.Writer.(*flushWriter); ok && w.policy.AtComponents {
+		w.Flush()
+	}
+}
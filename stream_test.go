@@ -0,0 +1,83 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// flushRecorder records the output sent to the client by each flush.
+type flushRecorder struct {
+	bytes.Buffer
+	flushed []string
+	sent    int
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushed = append(r.flushed, r.String()[r.sent:])
+	r.sent = r.Len()
+}
+
+func TestFlushThreshold(t *testing.T) {
+	recorder := new(flushRecorder)
+	c := RenderContext{Writer: recorder}.WithFlushPolicy(FlushPolicy{Threshold: 4})
+	for _, s := range []string{"ab", "cd", "e", "fghij", "k"} {
+		c.WriteString(s)
+	}
+	if flushed := fmt.Sprint(recorder.flushed); flushed != "[abcd efghij]" {
+		t.Errorf("unexpected flushes: %s", flushed)
+	}
+}
+
+func TestFlushAtComponents(t *testing.T) {
+	for _, test := range []struct {
+		policy  FlushPolicy
+		flushed string
+	}{
+		{FlushNever, "[]"},
+		{FlushAtComponents, "[<head>ready|]"},
+	} {
+		recorder := new(flushRecorder)
+		c := RenderContext{Writer: recorder}.WithFlushPolicy(test.policy)
+		c.WriteString("<head>")
+
+		ready := NewStrongoComponent(nil)
+		ready.OnDataReady(nil)
+		ready.RenderWhenReady(c, func(c RenderContext) error {
+			_, err := c.WriteString("ready")
+			return err
+		})
+		c.WriteString("|")
+
+		loading := NewStrongoComponent(nil)
+		time.AfterFunc(10*time.Millisecond, func() {
+			loading.OnDataReady(nil)
+		})
+		loading.RenderWhenReady(c, func(c RenderContext) error {
+			_, err := c.WriteString("loaded")
+			return err
+		})
+
+		if flushed := fmt.Sprint(recorder.flushed); flushed != test.flushed {
+			t.Errorf("%+v: expected flushes %s, got %s", test.policy, test.flushed, flushed)
+		}
+		if recorder.String() != "<head>ready|loaded" {
+			t.Errorf("%+v: unexpected output %q", test.policy, recorder.String())
+		}
+	}
+}
+
+func TestWithFlushPolicyResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	c := RenderContext{Writer: recorder}.WithFlushPolicy(FlushPolicy{Threshold: 1})
+	c.WriteString("x")
+	if !recorder.Flushed {
+		t.Error("http.ResponseWriter should be flushed")
+	}
+	c = RenderContext{Writer: new(bytes.Buffer)}
+	if c.WithFlushPolicy(FlushAtComponents) != c {
+		t.Error("render context should not be changed for a writer that can not flush")
+	}
+}
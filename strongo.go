@@ -1,7 +1,10 @@
 package templates
 
 import (
+	"context"
+	"errors"
 	"io"
+	"time"
 //	"log"
 //	"github.com/strongo/templates/text"
 )
@@ -22,12 +25,17 @@ type Template interface {
 	Name() string  // file name
 	Path() string  // path to file (should it include file name?)
 	Source() string  // source code of the template it was parsed from
-	GetData() error
+	GetData(ctx context.Context) error  // requests data; ctx is the Context of the RenderContext passed to Render
 	Render(c RenderContext) error
 }
 
 type I18n interface {
 	GetText(key string) string
+	// NGetText returns the plural form of the message for n with %d replaced by n,
+	// e.g. "5 сообщений" for NGetText("%d message", "%d messages", 5) in Russian.
+	NGetText(singular, plural string, n int) string
+	// Format fills named placeholders of a translated message, see FormatMessage.
+	Format(message string, args map[string]interface{}) string
 }
 
 type Block interface {
@@ -36,6 +44,35 @@ type Block interface {
 
 type RenderContext struct {
 	Writer io.Writer  // current writer
+	Context context.Context  // cancels data loading and rendering; context.Background() if nil
+
+	pipe *pipe // components deferred by out-of-order rendering; nil if rendering in order
+	fragment *fragment // fragment being rendered for the fragment cache; nil if none
+}
+
+// NewRenderContext creates a render context writing to the writer until ctx is done.
+// Its context carries the memo of ctx or a new one, so components share data
+// if GetData of the template is called with the Context of the render context.
+// Unless ctx is created by Scheduler.NewRender, work of the render is scheduled by DefaultScheduler.
+func NewRenderContext(ctx context.Context, writer io.Writer) RenderContext {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if MemoFrom(ctx) == nil {
+		ctx = WithMemo(ctx)
+	}
+	if _, ok := ctx.Value(renderQueueKey{}).(*renderQueue); !ok {
+		ctx = DefaultScheduler.NewRender(ctx)
+	}
+	return RenderContext{Writer: writer, Context: ctx}
+}
+
+// Ctx returns the context of the render, never nil.
+func (c RenderContext) Ctx() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
 }
 
 func (c RenderContext) WriteString(s string) (n int, err error) {
@@ -51,30 +88,159 @@ type StrongoTask struct {
 }
 
 type IStrongoComponent interface {
-	GetData()
+	// GetData requests data of the component. It returns an error if the data can not be requested;
+	// errors of the loading itself are reported with OnDataReady and returned by WhenDataReady.
+	// Loading should stop once ctx is done.
+	GetData(ctx context.Context) error
 }
 
+// ErrorPolicy defines what a component renders when loading of its data failed.
+type ErrorPolicy int
+
+const (
+	FailOnError     ErrorPolicy = iota // Render returns the error and the whole render fails.
+	FallbackOnError                    // The fallback content is rendered instead of the component.
+	SkipOnError                        // Nothing is rendered for the component.
+)
+
+// ErrTimeBudgetExceeded is returned by WhenDataReady when the data is not ready within the time budget of the component.
+var ErrTimeBudgetExceeded = errors.New("templates: component data is not ready within the time budget")
+
+// RenderFunc renders content to the render context.
+type RenderFunc func(c RenderContext) error
+
 type StrongoComponent struct {
-	semaphore chan int
+	semaphore chan error
 	components []IStrongoComponent
+	loaders []*Loader // dispatched once all components have requested their data
+
+	errorPolicy ErrorPolicy
+	fallback func(c RenderContext, err error) error
+	timeBudget time.Duration // how long rendering waits for the data; no limit if 0
+	priority Priority // priority of the data loading work of the component
 }
 
-func (c *StrongoComponent) OnDataReady(){
+// OnDataReady reports the data of the component is loaded, or failed to load with the error.
+func (c *StrongoComponent) OnDataReady(err error){
 //	log.Print("OnDataReady 1")
-	c.semaphore <- 1
+	c.semaphore <- err
 //	log.Print("OnDataReady 2")
 }
 
-func (c *StrongoComponent) WhenDataReady(){
+// WhenDataReady blocks until the data of the component is loaded and returns the loading error.
+// It returns the error of ctx if ctx is done first
+// and ErrTimeBudgetExceeded if the time budget of the component runs out.
+func (c *StrongoComponent) WhenDataReady(ctx context.Context) error {
+	if ready, err := c.dataReady(); ready {
+		return err
+	}
+	return c.waitData(ctx)
+}
+
+// dataReady reports whether the data of the component is ready, without blocking, and the loading error.
+func (c *StrongoComponent) dataReady() (ready bool, err error) {
+	select {
+	case err = <-c.semaphore:
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+// waitData blocks until the data is ready, ctx is done or the time budget runs out.
+func (c *StrongoComponent) waitData(ctx context.Context) error {
 //	log.Print("WhenDataReady 1")
-	<-c.semaphore
-//	log.Print("WhenDataReady 2")
+	var timeout <-chan time.Time
+	if c.timeBudget > 0 {
+		timer := time.NewTimer(c.timeBudget)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case err := <-c.semaphore:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return ErrTimeBudgetExceeded
+	}
+}
+
+// SetTimeBudget limits how long rendering waits for the data of the component.
+// Once the budget runs out RenderWhenReady renders the fallback instead, whatever the error policy is.
+func (c *StrongoComponent) SetTimeBudget(budget time.Duration) {
+	c.timeBudget = budget
+}
+
+// SetPriority sets the priority of the data loading work of the component, e.g. PriorityAboveTheFold.
+func (c *StrongoComponent) SetPriority(priority Priority) {
+	c.priority = priority
 }
 
-func NewStrongoComponent(components []IStrongoComponent) *StrongoComponent {
+// Go schedules data loading work of the component with its priority, see Go.
+func (c *StrongoComponent) Go(ctx context.Context, work func(ctx context.Context)) {
+	Go(ctx, c.priority, work)
+}
+
+// SetErrorPolicy sets what RenderWhenReady does if the data of the component failed to load.
+// The fallback renders content for FallbackOnError; nothing is rendered if it is nil.
+func (c *StrongoComponent) SetErrorPolicy(policy ErrorPolicy, fallback func(c RenderContext, err error) error) {
+	c.errorPolicy = policy
+	c.fallback = fallback
+}
+
+// RenderWhenReady waits for the data of the component and renders it with the render function.
+// If the data failed to load the error is handled according to the error policy of the component.
+// The error of a done render context is returned regardless of the policy.
+//
+// With out-of-order rendering a component whose data is not ready yet writes a placeholder
+// and is rendered by RenderContext.RenderPending once the data is ready.
+func (c *StrongoComponent) RenderWhenReady(rc RenderContext, render RenderFunc) error {
+	ready, err := c.dataReady()
+	if !ready {
+		if rc.pipe != nil {
+			return rc.pipe.postpone(rc, c, render)
+		}
+		rc.flushAtComponent()
+		err = c.waitData(rc.Ctx())
+	}
+	return c.render(rc, err, render)
+}
+
+// render renders the data of the component or handles the loading error according to the error policy.
+func (c *StrongoComponent) render(rc RenderContext, err error, render RenderFunc) error {
+	ctx := rc.Ctx()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		rc.fragment.spoil() // the fallback is not to be cached
+		policy := c.errorPolicy
+		if err == ErrTimeBudgetExceeded {
+			policy = FallbackOnError
+		}
+		switch policy {
+		case SkipOnError:
+			return nil
+		case FallbackOnError:
+			if c.fallback == nil {
+				return nil
+			}
+			return c.fallback(rc, err)
+		default:
+			return err
+		}
+	}
+	return render(rc)
+}
+
+// NewStrongoComponent creates a component getting data of the child components.
+// Loaders shared by the children batch their requests into one call per GetData pass.
+func NewStrongoComponent(components []IStrongoComponent, loaders ...*Loader) *StrongoComponent {
 	return &StrongoComponent{
-		semaphore: make(chan int, 1),
+		semaphore: make(chan error, 1),
 		components: components,
+		loaders: loaders,
 	}
 }
 
@@ -82,10 +248,18 @@ func (self StrongoComponent) Components() []IStrongoComponent {
 	return self.components
 }
 
-func (c StrongoComponent) GetData() {
+// GetData requests data of all the child components and dispatches the loaders.
+// It returns the first error of the children; other children still get their data.
+func (c StrongoComponent) GetData(ctx context.Context) (err error) {
 	if c.components != nil{
 		for _, component := range c.components {
-			component.GetData()
+			if componentErr := component.GetData(ctx); componentErr != nil && err == nil {
+				err = componentErr
+			}
 		}
 	}
+	for _, loader := range c.loaders {
+		Go(ctx, c.priority, loader.Dispatch)
+	}
+	return err
 }
@@ -0,0 +1,129 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type failingComponent struct {
+	err error
+}
+
+func (c failingComponent) GetData(ctx context.Context) error {
+	return c.err
+}
+
+func TestStrongoComponentErrorPolicy(t *testing.T) {
+	loadErr := errors.New("load failed")
+	renderData := func(c RenderContext) error {
+		_, err := c.WriteString("data")
+		return err
+	}
+	fallback := func(c RenderContext, err error) error {
+		_, e := c.WriteString("fallback: " + err.Error())
+		return e
+	}
+	tests := []struct {
+		name     string
+		policy   ErrorPolicy
+		fallback func(c RenderContext, err error) error
+		loadErr  error
+		output   string
+		err      error
+	}{
+		{"loaded", FailOnError, nil, nil, "data", nil},
+		{"fail", FailOnError, fallback, loadErr, "", loadErr},
+		{"fallback", FallbackOnError, fallback, loadErr, "fallback: load failed", nil},
+		{"fallback without content", FallbackOnError, nil, loadErr, "", nil},
+		{"skip", SkipOnError, fallback, loadErr, "", nil},
+	}
+	for _, test := range tests {
+		component := NewStrongoComponent(nil)
+		component.SetErrorPolicy(test.policy, test.fallback)
+		component.OnDataReady(test.loadErr)
+		writer := new(bytes.Buffer)
+		err := component.RenderWhenReady(RenderContext{Writer: writer}, renderData)
+		if err != test.err {
+			t.Errorf("%s: expected error %v, got %v", test.name, test.err, err)
+		}
+		if writer.String() != test.output {
+			t.Errorf("%s: expected output %q, got %q", test.name, test.output, writer.String())
+		}
+	}
+}
+
+func TestStrongoComponentGetDataError(t *testing.T) {
+	first, second := errors.New("first"), errors.New("second")
+	loader := NewLoader(func(ctx context.Context, keys []interface{}) (map[interface{}]interface{}, error) {
+		return nil, nil
+	})
+	dispatched := make(chan bool, 1)
+	loader.Load(1, func(value interface{}, err error) {
+		dispatched <- true
+	})
+	components := []IStrongoComponent{failingComponent{}, failingComponent{first}, failingComponent{second}}
+	if err := NewStrongoComponent(components, loader).GetData(context.Background()); err != first {
+		t.Errorf("expected the first error, got: %v", err)
+	}
+	<-dispatched // Loaders are dispatched despite the error.
+}
+
+func TestStrongoComponentCancel(t *testing.T) {
+	component := NewStrongoComponent(nil)
+	component.SetErrorPolicy(FallbackOnError, func(c RenderContext, err error) error {
+		t.Error("fallback should not be rendered for a cancelled render")
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- component.RenderWhenReady(NewRenderContext(ctx, new(bytes.Buffer)), func(c RenderContext) error {
+			t.Error("data is never ready")
+			return nil
+		})
+	}()
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestStrongoComponentTimeBudget(t *testing.T) {
+	fallback := func(c RenderContext, err error) error {
+		_, e := c.WriteString("placeholder")
+		return e
+	}
+	renderData := func(c RenderContext) error {
+		_, err := c.WriteString("data")
+		return err
+	}
+
+	late := NewStrongoComponent(nil)
+	late.SetErrorPolicy(FailOnError, fallback)
+	late.SetTimeBudget(time.Millisecond)
+	writer := new(bytes.Buffer)
+	if err := late.RenderWhenReady(RenderContext{Writer: writer}, renderData); err != nil {
+		t.Fatal(err)
+	}
+	if writer.String() != "placeholder" {
+		t.Errorf("late component should render the fallback, got: %q", writer.String())
+	}
+	late.OnDataReady(nil) // Data arriving after the budget does not block the loader.
+
+	ready := NewStrongoComponent(nil)
+	ready.SetErrorPolicy(FailOnError, fallback)
+	ready.SetTimeBudget(time.Second)
+	time.AfterFunc(time.Millisecond, func() {
+		ready.OnDataReady(nil)
+	})
+	writer.Reset()
+	if err := ready.RenderWhenReady(RenderContext{Writer: writer}, renderData); err != nil {
+		t.Fatal(err)
+	}
+	if writer.String() != "data" {
+		t.Errorf("component ready within the budget should render the data, got: %q", writer.String())
+	}
+}